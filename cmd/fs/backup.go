@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func (a *app) newBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Create, list and restore database backups",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "create",
+		Short: "Back up the shortcut database now",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := a.store.CreateBackup(storage.DefaultBackupDir(a.dbPath), a.cfg.Backup.Keep)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Created backup %s (%s)\n", b.ID, b.Path)
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List database backups",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backups, err := storage.ListBackups(storage.DefaultBackupDir(a.dbPath))
+			if err != nil {
+				return err
+			}
+
+			if len(backups) == 0 {
+				fmt.Fprintln(a.stdout, "No backups found. Create one with: fs backup create")
+				return nil
+			}
+
+			fmt.Fprintln(a.stdout, "Backups (newest first):")
+			for _, b := range backups {
+				fmt.Fprintf(a.stdout, "  %s  %s  %s\n", b.ID, b.CreatedAt.Format("2006-01-02 15:04:05"), humanize.Bytes(uint64(b.Size)))
+			}
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "restore <id>",
+		Short: "Replace the shortcut database with a backup",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := storage.DefaultBackupDir(a.dbPath)
+
+			b, err := storage.FindBackup(dir, args[0])
+			if err != nil {
+				return err
+			}
+
+			// Check the backup before touching the current database
+			if err := storage.VerifyBackup(b.Path); err != nil {
+				return err
+			}
+
+			// Keep the current state around in case the restore was a mistake.
+			// Rotation waits until the backup being restored has been read.
+			current, err := a.store.CreateBackup(dir, 0)
+			if err != nil {
+				return err
+			}
+
+			a.close()
+			if err := storage.RestoreBackup(b.Path, a.dbPath); err != nil {
+				return err
+			}
+			if err := storage.PruneBackups(dir, a.cfg.Backup.Keep); err != nil {
+				fmt.Fprintf(a.stderr, "Warning: %v\n", err)
+			}
+
+			// Reopen so the rest of the command sees the restored database
+			a.store, err = storage.NewSQLiteStorage(a.dbPath)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Restored backup %s\n", b.ID)
+			fmt.Fprintf(a.stdout, "  Previous database saved as backup %s\n", current.ID)
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+// Take a backup if the newest one is older than the backup interval, and
+// rotate out extra backups, such as those taken before a migration.
+// Runs before the commands in upkeepCommands, so failures only warn.
+func (a *app) autoBackup() {
+	dir := storage.DefaultBackupDir(a.dbPath)
+	backups, err := storage.ListBackups(dir)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "Warning: %v\n", err)
+		return
+	}
+
+	interval := time.Duration(a.cfg.Backup.Interval)
+	if interval > 0 && (len(backups) == 0 || a.now().Sub(backups[0].CreatedAt) >= interval) {
+		if _, err := a.store.CreateBackup(dir, a.cfg.Backup.Keep); err != nil {
+			fmt.Fprintf(a.stderr, "Warning: %v\n", err)
+		}
+		return
+	}
+
+	if len(backups) > a.cfg.Backup.Keep {
+		if err := storage.PruneBackups(dir, a.cfg.Backup.Keep); err != nil {
+			fmt.Fprintf(a.stderr, "Warning: %v\n", err)
+		}
+	}
+}
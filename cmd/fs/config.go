@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mikul1999-pixel/fs/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func (a *app) newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and change settings in the config file",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, err := a.cfg.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(a.stdout, value)
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Write a setting to the config file (lists are comma separated)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+			path := config.GetConfigPath()
+
+			if err := config.SetInFile(path, key, value); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Set %s in %s\n", key, path)
+
+			// Tell the user when the new value won't take effect
+			for _, s := range a.cfg.Settings(path) {
+				if s.Key == key && strings.HasPrefix(s.Source, "env ") {
+					fmt.Fprintf(a.stdout, "  Note: overridden by %s\n", strings.TrimPrefix(s.Source, "env "))
+				}
+			}
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List every setting with its value and source",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			settings := a.cfg.Settings(config.GetConfigPath())
+
+			width := 0
+			for _, s := range settings {
+				width = max(width, len(s.Key)+len(s.Value)+3)
+			}
+
+			for _, s := range settings {
+				line := fmt.Sprintf("%s = %s", s.Key, s.Value)
+				fmt.Fprintf(a.stdout, "%-*s  # %s\n", width, line, s.Source)
+			}
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in $EDITOR and check it afterwards",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := config.GetConfigPath()
+
+			if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					return fmt.Errorf("failed to create config directory: %w", err)
+				}
+				if err := os.WriteFile(path, []byte(config.Template), 0644); err != nil {
+					return fmt.Errorf("failed to create config: %w", err)
+				}
+			}
+
+			editor := os.Getenv("VISUAL")
+			if editor == "" {
+				editor = os.Getenv("EDITOR")
+			}
+			if editor == "" {
+				editor = "vi"
+			}
+
+			// Run through the shell so editors with arguments like "code -w" work
+			editCmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+			editCmd.Stdin = a.stdin
+			editCmd.Stdout = a.stdout
+			editCmd.Stderr = a.stderr
+
+			if err := editCmd.Run(); err != nil {
+				return fmt.Errorf("running editor: %w", err)
+			}
+
+			if err := config.LoadFile(path, config.Default()); err != nil {
+				return fmt.Errorf("config has problems:\n%v", err)
+			}
+
+			fmt.Fprintf(a.stdout, "Config OK: %s\n", path)
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "path",
+		Short: "Print the location of the config file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Fprintln(a.stdout, config.GetConfigPath())
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+// Whether cmd is a config subcommand, which must keep working when the
+// config file itself is broken
+func isConfigCommand(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd.Name() == "config" && cmd.Parent() != nil && !cmd.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
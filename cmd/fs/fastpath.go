@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/pkg/config"
+)
+
+// `fs go <name>` runs on every f, so it is answered from the name cache
+// before cobra or SQLite are touched. Anything the cache can't settle
+// (flags, unknown names, a stale cache) takes the normal path,
+// which also refreshes the cache when the store is closed. The installed fs
+// is built without the TUI packages (tui_helper.go), so their init functions
+// don't run here either.
+func (a *app) fastGo(args []string) bool {
+	if len(args) != 2 || args[0] != "go" || strings.HasPrefix(args[1], "-") {
+		return false
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return false
+	}
+
+	dbPath, err := a.expandPath(cfg.DB.Path)
+	if err != nil {
+		return false
+	}
+
+	path, ok := storage.LookupCache(dbPath, args[1])
+	if !ok {
+		return false
+	}
+
+	fmt.Fprintln(a.stdout, path)
+	_ = storage.LogJump(dbPath, a.jumpTo(args[1], path))
+	return true
+}
+
+// `fs prompt` with no arguments, answered from the cache the same way
+func (a *app) fastPrompt(args []string) bool {
+	if len(args) != 1 || args[0] != "prompt" {
+		return false
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return false
+	}
+	parts, err := config.ParsePromptFormat(cfg.Prompt.Format)
+	if err != nil {
+		return false
+	}
+
+	dbPath, err := a.expandPath(cfg.DB.Path)
+	if err != nil {
+		return false
+	}
+	shortcuts, ok := storage.ReadCache(dbPath)
+	if !ok {
+		return false
+	}
+
+	dir, err := a.getwd()
+	if err != nil {
+		return false
+	}
+
+	a.printSegment(promptSegment(parts, cfg.Prompt, shortcuts, dir))
+	return true
+}
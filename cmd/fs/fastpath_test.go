@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mikul1999-pixel/fs/pkg/config"
+)
+
+func fastGoApp(stdout io.Writer) *app {
+	a := newApp()
+	a.stdout = stdout
+	a.stderr = io.Discard
+	return a
+}
+
+func TestFastGo_AnswersFromCache(t *testing.T) {
+	isolateConfig(t)
+	target := t.TempDir()
+	runFS(t, "add", "proj", target)
+
+	var out bytes.Buffer
+	if !fastGoApp(&out).fastGo([]string{"go", "proj"}) {
+		t.Fatal("expected fast path to answer from the cache")
+	}
+	if strings.TrimSpace(out.String()) != target {
+		t.Fatalf("expected %s, got %q", target, out.String())
+	}
+
+	runFS(t, "edit-path", "proj", t.TempDir())
+	out.Reset()
+	if !fastGoApp(&out).fastGo([]string{"go", "proj"}) {
+		t.Fatal("expected cache to be refreshed after edit-path")
+	}
+	if strings.TrimSpace(out.String()) == target {
+		t.Fatal("expected fast path to see the edited path")
+	}
+}
+
+func TestFastGo_CountsVisits(t *testing.T) {
+	isolateConfig(t)
+	runFS(t, "add", "proj", t.TempDir())
+
+	for i := 0; i < 2; i++ {
+		if !fastGoApp(io.Discard).fastGo([]string{"go", "proj"}) {
+			t.Fatal("expected fast path to answer from the cache")
+		}
+	}
+
+	runFS(t, "list")
+	if sc := getShortcut(t, config.GetDBPath(), "proj"); sc.Visits != 2 {
+		t.Fatalf("expected the logged visits to be counted, got %d", sc.Visits)
+	}
+	if !fastGoApp(io.Discard).fastGo([]string{"go", "proj"}) {
+		t.Fatal("expected the cache to be rewritten after folding visits")
+	}
+}
+
+func TestFastGo_FallsBack(t *testing.T) {
+	isolateConfig(t)
+	runFS(t, "add", "proj", t.TempDir())
+
+	for _, args := range [][]string{
+		{"go", "missing"},
+		{"go", "--db", "x.db"},
+		{"go"},
+		{"ls", "proj"},
+	} {
+		if fastGoApp(io.Discard).fastGo(args) {
+			t.Fatalf("expected %v to take the normal path", args)
+		}
+	}
+}
+
+func TestFastPrompt_AnswersFromCache(t *testing.T) {
+	isolateConfig(t)
+	t.Setenv("FS_PROMPT_FORMAT", "{name}{:subpath}{ #tags}")
+	proj := t.TempDir()
+	runFS(t, "add", "proj", proj)
+	runFS(t, "tag", "proj", "go", "work")
+
+	var out bytes.Buffer
+	a := fastGoApp(&out)
+	a.getwd = func() (string, error) { return filepath.Join(proj, "cmd", "fs"), nil }
+	if !a.fastPrompt([]string{"prompt"}) {
+		t.Fatal("expected fast path to answer from the cache")
+	}
+	if got := out.String(); got != "proj:cmd/fs #go,work\n" {
+		t.Fatalf("expected the segment with tags, got %q", got)
+	}
+
+	// Outside every shortcut there's nothing to show
+	out.Reset()
+	a.getwd = func() (string, error) { return t.TempDir(), nil }
+	if !a.fastPrompt([]string{"prompt"}) || out.Len() != 0 {
+		t.Fatalf("expected an empty answer, got %q", out.String())
+	}
+
+	if fastGoApp(io.Discard).fastPrompt([]string{"prompt", "--format", "{name}"}) {
+		t.Fatal("expected flags to take the normal path")
+	}
+}
+
+// Compare with: go test ./cmd/fs -run '^$' -bench Go
+func BenchmarkGo(b *testing.B) {
+	home := b.TempDir()
+	b.Setenv("XDG_CONFIG_HOME", home)
+	b.Setenv("FS_CONFIG", "")
+	b.Setenv("FS_DB", "")
+	b.Setenv("FS_BACKUP_INTERVAL", "0")
+
+	if code := fastGoApp(io.Discard).run([]string{"add", "proj", home}); code != 0 {
+		b.Fatalf("add exited %d", code)
+	}
+
+	b.Run("cobra+sqlite", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if code := fastGoApp(io.Discard).run([]string{"go", "proj"}); code != 0 {
+				b.Fatalf("go exited %d", code)
+			}
+		}
+	})
+
+	b.Run("fast-path", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if !fastGoApp(io.Discard).fastGo([]string{"go", "proj"}) {
+				b.Fatal("fast path missed")
+			}
+		}
+	})
+}
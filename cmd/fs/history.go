@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func (a *app) newUndoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "undo",
+		Short: "Revert the last change to your shortcuts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			op, err := a.store.Undo()
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Undid: %s\n", describeOperation(*op))
+			return nil
+		},
+	}
+}
+
+func (a *app) newRedoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "redo",
+		Short: "Reapply the last undone change",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			op, err := a.store.Redo()
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Redid: %s\n", describeOperation(*op))
+			return nil
+		},
+	}
+}
+
+func (a *app) newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List recent changes to your shortcuts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			ops, err := a.store.History(limit)
+			if err != nil {
+				return err
+			}
+
+			if len(ops) == 0 {
+				fmt.Fprintln(a.stdout, "No history yet")
+				return nil
+			}
+
+			fmt.Fprintln(a.stdout, "History (newest first):")
+			for _, op := range ops {
+				undone := ""
+				if op.Undone {
+					undone = " (undone)"
+				}
+				fmt.Fprintf(a.stdout, "  %4d  %s  %s%s\n", op.ID, op.CreatedAt.Local().Format("2006-01-02 15:04:05"), describeOperation(op), undone)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntP("limit", "n", 20, "Number of operations to show")
+	return cmd
+}
+
+// One line summary of a journaled operation
+func describeOperation(op storage.Operation) string {
+	switch op.Kind {
+	case storage.OpAdd:
+		if op.After != nil {
+			return fmt.Sprintf("add %s -> %s", op.After.Name, op.After.Path)
+		}
+	case storage.OpDelete:
+		if op.Before != nil {
+			return fmt.Sprintf("rm %s -> %s%s", op.Before.Name, op.Before.Path, formatTags(op.Before.Tags))
+		}
+	case storage.OpRestore:
+		if op.After != nil {
+			return fmt.Sprintf("restore %s -> %s%s", op.After.Name, op.After.Path, formatTags(op.After.Tags))
+		}
+	case storage.OpEditPath:
+		if op.Before != nil && op.After != nil {
+			return fmt.Sprintf("edit-path %s: %s -> %s", op.After.Name, op.Before.Path, op.After.Path)
+		}
+	case storage.OpEditName:
+		if op.Before != nil && op.After != nil {
+			return fmt.Sprintf("edit-name %s -> %s", op.Before.Name, op.After.Name)
+		}
+	case storage.OpTag, storage.OpUntag:
+		if op.Before != nil && op.After != nil {
+			changed := tagDiff(op.After.Tags, op.Before.Tags)
+			if op.Kind == storage.OpUntag {
+				changed = tagDiff(op.Before.Tags, op.After.Tags)
+			}
+			return fmt.Sprintf("%s %s: %s", op.Kind, op.Shortcut, strings.Join(changed, ", "))
+		}
+	}
+
+	return fmt.Sprintf("%s %s", op.Kind, op.Shortcut)
+}
+
+// Tags in a that aren't in b
+func tagDiff(a, b []string) []string {
+	seen := make(map[string]struct{}, len(b))
+	for _, tag := range b {
+		seen[tag] = struct{}{}
+	}
+
+	var diff []string
+	for _, tag := range a {
+		if _, ok := seen[tag]; !ok {
+			diff = append(diff, tag)
+		}
+	}
+	return diff
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", strings.Join(tags, ", "))
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Every cd made through fs is a jump of the shell's session, so fs back and
+// fs forward can walk through them. Shells set up by `fs init` export their
+// PID as FS_SESSION, which keeps terminals apart.
+
+// Session jumps are recorded under: FS_SESSION, or the parent's PID
+func session() string {
+	if id := os.Getenv("FS_SESSION"); id != "" {
+		return id
+	}
+	return strconv.Itoa(os.Getppid())
+}
+
+// The jump to path that a shell function is about to cd to
+func (a *app) jumpTo(name, path string) storage.Jump {
+	from, _ := a.getwd()
+	return storage.Jump{Session: session(), Shortcut: name, From: from, Path: path, At: a.now()}
+}
+
+func (a *app) newBackCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "back [steps]",
+		Short: "Print where this shell was before its last jump",
+		Long: `Step back through the jumps this shell made with fs and print where the
+shell was before them. The fback function from fs init cd's there.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.stepJumps(args, a.store.Back, func(j *storage.Jump) string { return j.From })
+		},
+	}
+}
+
+func (a *app) newForwardCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "forward [steps]",
+		Short: "Print where this shell's next jump went, after fs back",
+		Long: `Retake jumps that fs back stepped back past and print where they went.
+The ffwd function from fs init cd's there.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.stepJumps(args, a.store.Forward, func(j *storage.Jump) string { return j.Path })
+		},
+	}
+}
+
+// Take up to steps steps and print where the last one lands
+func (a *app) stepJumps(args []string, step func(string) (*storage.Jump, error), dest func(*storage.Jump) string) error {
+	steps := 1
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid steps '%s': expected a number of 1 or more", args[0])
+		}
+		steps = n
+	}
+
+	var last *storage.Jump
+	for i := 0; i < steps; i++ {
+		jump, err := step(session())
+		if err != nil {
+			if last != nil {
+				break
+			}
+			return err
+		}
+		last = jump
+	}
+	fmt.Fprintln(a.stdout, dest(last))
+	return nil
+}
+
+func (a *app) newHistCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hist",
+		Short: "Pick a directory this shell jumped to",
+		Long: `Pick one of the directories this shell jumped to with fs, most recent
+first, and print it. The fhist function from fs init cd's there.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, _ := cmd.Flags().GetBool("list")
+			limit, _ := cmd.Flags().GetInt("limit")
+			plain, _ := cmd.Flags().GetBool("plain")
+			numbered, _ := cmd.Flags().GetBool("numbered")
+
+			jumps, err := a.store.Jumps(session(), limit)
+			if err != nil {
+				return err
+			}
+			if len(jumps) == 0 {
+				fmt.Fprintln(a.stderr, "No jumps in this shell yet")
+				return &exitError{code: 1}
+			}
+
+			if list {
+				fmt.Fprintln(a.stdout, "Jumps (newest first):")
+				for _, j := range jumps {
+					undone := ""
+					if j.Undone {
+						undone = " (gone back past)"
+					}
+					fmt.Fprintf(a.stdout, "  %s  %s -> %s%s\n", j.At.Local().Format("2006-01-02 15:04:05"), j.Shortcut, j.Path, undone)
+				}
+				return nil
+			}
+
+			// Each directory once, as of its latest jump
+			var places []storage.Shortcut
+			seen := make(map[string]bool)
+			for _, j := range jumps {
+				if !seen[j.Path] {
+					seen[j.Path] = true
+					places = append(places, storage.Shortcut{Name: j.Shortcut, Path: j.Path, LastVisitedAt: j.At})
+				}
+			}
+
+			picked, err := a.pickPlace(places, plain, numbered || a.numberedSelector())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(a.stdout, picked.Path)
+			_ = a.store.RecordJump(a.jumpTo(picked.Name, picked.Path))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolP("list", "l", false, "List the jumps instead of picking one")
+	cmd.Flags().IntP("limit", "n", 50, "Number of jumps to look back through")
+	cmd.Flags().BoolP("plain", "p", false, "Disable selector colors")
+	cmd.Flags().Bool("numbered", false, "Ask with a plain numbered list instead of the full-screen picker (default from selector.mode)")
+	return cmd
+}
+
+// One of places, from the selector or the numbered list
+func (a *app) pickPlace(places []storage.Shortcut, plain, numbered bool) (*storage.Shortcut, error) {
+	if len(places) == 1 {
+		return &places[0], nil
+	}
+
+	theme, keymap, err := a.selectorLook()
+	if err != nil {
+		return nil, err
+	}
+	opts := ui.SelectorOptions{
+		NoColor: plain || a.cfg.Selector.Theme == "none",
+		Theme:   theme,
+		Keymap:  keymap,
+		Sort:    ui.SortByRecent,
+		Now:     a.now,
+		Mouse:   a.cfg.Selector.Mouse,
+	}
+
+	if numbered || !a.interactive() {
+		picked, err := ui.Prompt(places, opts, a.stdin, a.stderr)
+		if err != nil {
+			return nil, &exitError{code: 1, err: ignoreNoChoice(err)}
+		}
+		return &picked[0], nil
+	}
+
+	picked, err := a.selectShortcut(places, opts)
+	if err != nil {
+		return nil, &exitError{code: 1}
+	}
+	return picked, nil
+}
+
+// Nothing picked needs no message
+func ignoreNoChoice(err error) error {
+	if errors.Is(err, ui.ErrNoChoice) {
+		return nil
+	}
+	return err
+}
@@ -1,45 +1,280 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/mikul1999-pixel/fs/internal/storage"
 	"github.com/mikul1999-pixel/fs/internal/ui"
 	"github.com/mikul1999-pixel/fs/pkg/config"
 	"github.com/spf13/cobra"
 )
 
-var store storage.Storage
+// app holds everything a command needs, so the whole CLI can run in-process
+// against a temp database and captured output
+type app struct {
+	store  storage.Storage
+	cfg    *config.Config
+	dbPath string
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	now   func() time.Time
+	getwd func() (string, error)
+	// Whether there is a terminal to run the selector in
+	interactive func() bool
+
+	// Set once arguments are parsed, errors before that are usage mistakes
+	parsed bool
+
+	// Interactive selectors, swapped out in tests
+	selectShortcut  func([]storage.Shortcut, ui.SelectorOptions) (*storage.Shortcut, error)
+	selectShortcuts func([]storage.Shortcut, ui.SelectorOptions) ([]storage.Shortcut, error)
+	manageShortcuts func([]storage.Shortcut, ui.ManagerOptions) (*storage.Shortcut, error)
+}
+
+func newApp() *app {
+	return &app{
+		stdin:           os.Stdin,
+		stdout:          os.Stdout,
+		stderr:          os.Stderr,
+		now:             time.Now,
+		getwd:           os.Getwd,
+		interactive:     func() bool { return isTerminal(os.Stdin) && isTerminal(os.Stderr) },
+		selectShortcut:  runSelector,
+		selectShortcuts: runMultiSelector,
+		manageShortcuts: runManager,
+	}
+}
+
+// exitError ends a command with a specific exit status. A nil err means the
+// command already told the user what went wrong.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
 
-var rootCmd = &cobra.Command{
-	Use:   "fs",
-	Short: "Filesystem shortcut toolkit",
-	Long:  `A CLI tool for managing filesystem shortcuts, tags, and quick navigation`,
+func (e *exitError) Unwrap() error {
+	return e.err
 }
 
-var initCmd = &cobra.Command{
-	Use:   "init [jump-name] [find-name]",
-	Short: "Setup functions for shell integration",
-	Args:  cobra.RangeArgs(0, 2),
-	Run: func(cmd *cobra.Command, args []string) {
-		jumpFn := "f"
-		findFn := "ff"
+// exitAmbiguous is the status of fs find when several shortcuts match and
+// nothing picked one
+const exitAmbiguous = 2
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
 
-		if len(args) >= 1 && args[0] != "" {
-			jumpFn = args[0]
+// Run fs with args and return the exit status
+func (a *app) run(args []string) int {
+	root := a.newRootCmd()
+	root.SetArgs(args)
+	root.SetIn(a.stdin)
+	root.SetOut(a.stdout)
+	root.SetErr(a.stderr)
+
+	cmd, err := root.ExecuteC()
+	a.close()
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		if exitErr.err != nil {
+			fmt.Fprintf(a.stderr, "Error: %v\n", exitErr.err)
 		}
-		if len(args) >= 2 && args[1] != "" {
-			findFn = args[1]
+		return exitErr.code
+	}
+
+	fmt.Fprintf(a.stderr, "Error: %v\n", err)
+	if !a.parsed {
+		fmt.Fprint(a.stderr, cmd.UsageString())
+	}
+	return 1
+}
+
+func (a *app) newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "fs",
+		Short:         "Filesystem shortcut toolkit",
+		Long:          `A CLI tool for managing filesystem shortcuts, tags, and quick navigation`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			a.parsed = true
+			return a.setup(cmd)
+		},
+	}
+
+	root.PersistentFlags().String("db", "", "Path to the shortcut database (default from db.path or FS_DB)")
+
+	root.AddCommand(a.newAddCmd())
+	root.AddCommand(a.newListCmd())
+	root.AddCommand(a.newDeleteCmd())
+	root.AddCommand(a.newEditPathCmd())
+	root.AddCommand(a.newEditNameCmd())
+	root.AddCommand(a.newInitCmd())
+	root.AddCommand(a.newGoCmd())
+	root.AddCommand(a.newPeekCmd())
+	root.AddCommand(a.newTagCmd())
+	root.AddCommand(a.newUntagCmd())
+	root.AddCommand(a.newFindCmd())
+	root.AddCommand(a.newUICmd())
+	root.AddCommand(a.newUndoCmd())
+	root.AddCommand(a.newRedoCmd())
+	root.AddCommand(a.newHistoryCmd())
+	root.AddCommand(a.newBackCmd())
+	root.AddCommand(a.newForwardCmd())
+	root.AddCommand(a.newHistCmd())
+	root.AddCommand(a.newWhichCmd())
+	root.AddCommand(a.newPromptCmd())
+	root.AddCommand(a.newTrashCmd())
+	root.AddCommand(a.newBackupCmd())
+	root.AddCommand(a.newConfigCmd())
+
+	return root
+}
+
+// Load config and open the database for the command about to run
+func (a *app) setup(cmd *cobra.Command) error {
+	// A broken config file must not lock you out of fixing it
+	cfg, err := config.Load()
+	if err != nil {
+		if !isConfigCommand(cmd) {
+			return fmt.Errorf("invalid config:\n%v\nFix it with: fs config edit", err)
 		}
+		fmt.Fprintf(a.stderr, "Warning: invalid config:\n%v\n", err)
+		cfg = config.Default()
+	}
+	a.cfg = cfg
+
+	// --db beats FS_DB and db.path
+	if flag := cmd.Flags().Lookup("db"); flag != nil && flag.Changed {
+		a.cfg.DB.Path = flag.Value.String()
+	}
+
+	a.dbPath, err = a.expandPath(a.cfg.DB.Path)
+	if err != nil {
+		return fmt.Errorf("invalid database path: %w", err)
+	}
+
+	// Initialize storage
+	a.store, err = storage.NewSQLiteStorage(a.dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	// Lookups such as go and prompt run all the time and must stay quick
+	if runsUpkeep(cmd) {
+		a.purgeTrash()
+		a.autoBackup()
+	}
+	return nil
+}
+
+// Commands that look after the trash and backups: those that write shortcuts,
+// and those that show or pick from them, so expired trash never shows up
+var upkeepCommands = map[string]bool{
+	"fs add":           true,
+	"fs delete":        true,
+	"fs edit-path":     true,
+	"fs edit-name":     true,
+	"fs tag":           true,
+	"fs untag":         true,
+	"fs undo":          true,
+	"fs redo":          true,
+	"fs find":          true,
+	"fs ui":            true,
+	"fs trash list":    true,
+	"fs trash restore": true,
+}
 
-		fmt.Print(renderInitScript(jumpFn, findFn))
-	},
+func runsUpkeep(cmd *cobra.Command) bool {
+	return upkeepCommands[cmd.CommandPath()]
+}
+
+func (a *app) close() {
+	if a.store != nil {
+		_ = a.store.Close()
+		a.store = nil
+	}
 }
 
+func (a *app) newInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init [jump-name] [find-name]",
+		Short: "Setup functions for shell integration",
+		Long: `Print the shell functions for fs, to eval from your shell config. They
+are POSIX, for bash and zsh; fish gets its own with --shell fish.
+With --prompt, print a prompt hook showing the shortcut you're in instead:
+for bash, zsh or fish (default: from $SHELL), or a starship module.`,
+		Args: cobra.RangeArgs(0, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prompt, _ := cmd.Flags().GetBool("prompt")
+			shell, _ := cmd.Flags().GetString("shell")
+			if prompt {
+				if len(args) > 0 {
+					return fmt.Errorf("function names don't apply with --prompt")
+				}
+				if shell == "" {
+					shell = detectShell()
+				}
+				script, err := renderPromptScript(shell)
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(a.stdout, script)
+				return nil
+			}
+
+			jumpFn := "f"
+			findFn := "ff"
+
+			if len(args) >= 1 && args[0] != "" {
+				jumpFn = args[0]
+			}
+			if len(args) >= 2 && args[1] != "" {
+				findFn = args[1]
+			}
+
+			switch shell {
+			case "", "sh", "bash", "zsh":
+				fmt.Fprint(a.stdout, renderInitScript(jumpFn, findFn))
+			case "fish":
+				fmt.Fprint(a.stdout, renderFishInitScript(jumpFn, findFn))
+			case "starship":
+				return fmt.Errorf("starship only applies with --prompt")
+			default:
+				return fmt.Errorf("unknown shell %q: expected bash, zsh or fish", shell)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("prompt", false, "Print a prompt hook instead of the functions")
+	cmd.Flags().String("shell", "", "Shell to write for: bash|zsh|fish, or starship with --prompt")
+	return cmd
+}
+
+// Shell functions stay POSIX. Avoid path/status as locals: zsh ties them to PATH and $?
 func renderInitScript(jumpFn, findFn string) string {
 	return fmt.Sprintf(`
 # fs shell integration
@@ -50,233 +285,397 @@ func renderInitScript(jumpFn, findFn string) string {
         return 2
     fi
 
-    local path
-    path="$(fs go "$1")" || return $?
+    local dest
+    dest="$(fs go "$1")" || return $?
 
-    if [ -z "$path" ]; then
+    if [ -z "$dest" ]; then
         return 1
     fi
 
-    if [ ! -d "$path" ]; then
-        echo "fs: shortcut '$1' points to missing directory: $path" >&2
+    if [ ! -d "$dest" ]; then
+        echo "fs: shortcut '$1' points to missing directory: $dest" >&2
         return 1
     fi
 
-    cd "$path"
+    cd "$dest"
 }
 
 %s() {
-    local path
-    path=$(fs find "$@" </dev/tty)
-    local status=$?
+    local dest rc
+    # Without a terminal (scripts, editors) fs find lists the matches and
+    # reads a number from stdin, or exits 2
+    if [ -t 0 ]; then
+        dest=$(fs find "$@" </dev/tty)
+    else
+        dest=$(fs find "$@")
+    fi
+    rc=$?
 
-    if [ $status -ne 0 ]; then
-        return $status
+    if [ $rc -ne 0 ]; then
+        return $rc
     fi
 
-    if [ -z "$path" ]; then
+    if [ -z "$dest" ]; then
         return 1
     fi
 
-    if [ ! -d "$path" ]; then
-        echo "fs: selected path is not a directory: $path" >&2
+    if [ ! -d "$dest" ]; then
+        echo "fs: selected path is not a directory: $dest" >&2
         return 1
     fi
 
-    cd "$path"
+    cd "$dest"
+}
+
+# Each shell keeps its own trail of jumps for fback, ffwd and fhist
+export FS_SESSION=$$
+
+__fs_cd() {
+    local dest
+    dest="$("$@")" || return $?
+
+    if [ -z "$dest" ]; then
+        return 1
+    fi
+
+    if [ ! -d "$dest" ]; then
+        echo "fs: not a directory: $dest" >&2
+        return 1
+    fi
+
+    cd "$dest"
+}
+
+fback() {
+    __fs_cd fs back "$@"
+}
+
+ffwd() {
+    __fs_cd fs forward "$@"
+}
+
+fhist() {
+    __fs_cd fs hist "$@" </dev/tty
 }
 `, jumpFn, jumpFn, findFn)
 }
 
-// func detectShell() string {
-// 	// dummy function to leave option open: detect shell & output different init?
-// 	shell := os.Getenv("SHELL")
-// 	if strings.Contains(shell, "zsh") {
-// 		return ".zshrc"
-// 	}
-// 	return ".bashrc"
-// }
+// The same functions for fish, which can't eval POSIX ones
+func renderFishInitScript(jumpFn, findFn string) string {
+	return fmt.Sprintf(`
+# fs shell integration for fish
 
-var goCmd = &cobra.Command{
-	Use:   "go <name>",
-	Short: "Get path for a shortcut",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		name := args[0]
+function %[1]s
+    if test (count $argv) -ne 1
+        echo "Usage: %[1]s <shortcut>" >&2
+        return 2
+    end
 
-		sc, err := store.GetShortcut(name)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+    set -l dest (fs go $argv[1])
+    or return $status
+
+    if test -z "$dest"
+        return 1
+    end
+
+    if not test -d "$dest"
+        echo "fs: shortcut '$argv[1]' points to missing directory: $dest" >&2
+        return 1
+    end
+
+    cd $dest
+end
+
+function %[2]s
+    set -l dest
+    set -l rc
+    # Without a terminal (scripts, editors) fs find lists the matches and
+    # reads a number from stdin, or exits 2
+    if isatty stdin
+        set dest (fs find $argv </dev/tty)
+        set rc $status
+    else
+        set dest (fs find $argv)
+        set rc $status
+    end
+
+    if test $rc -ne 0
+        return $rc
+    end
+
+    if test -z "$dest"
+        return 1
+    end
+
+    if not test -d "$dest"
+        echo "fs: selected path is not a directory: $dest" >&2
+        return 1
+    end
+
+    cd $dest
+end
 
-		// Print the path
-		// called by f(). print path --> jump with cd
-		fmt.Println(sc.Path)
-	},
-}
-
-var addCmd = &cobra.Command{
-	Use:   "add <name> [path]",
-	Short: "Add a new shortcut",
-	Args:  cobra.RangeArgs(1, 2),
-	Run: func(cmd *cobra.Command, args []string) {
-		var path, name string
-		if len(args) == 1 {
-			name = args[0]
-			cwd, err := os.Getwd()
+# Each shell keeps its own trail of jumps for fback, ffwd and fhist
+set -gx FS_SESSION $fish_pid
+
+function __fs_cd
+    set -l dest ($argv)
+    or return $status
+
+    if test -z "$dest"
+        return 1
+    end
+
+    if not test -d "$dest"
+        echo "fs: not a directory: $dest" >&2
+        return 1
+    end
+
+    cd $dest
+end
+
+function fback
+    __fs_cd fs back $argv
+end
+
+function ffwd
+    __fs_cd fs forward $argv
+end
+
+function fhist
+    __fs_cd fs hist $argv </dev/tty
+end
+`, jumpFn, findFn)
+}
+
+// The shell `fs init --prompt` writes a hook for when --shell is left out
+func detectShell() string {
+	switch shell := filepath.Base(os.Getenv("SHELL")); shell {
+	case "zsh", "fish":
+		return shell
+	}
+	return "bash"
+}
+
+func (a *app) newGoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "go <name>",
+		Short: "Get path for a shortcut",
+		Long: `Print the path for a shortcut. "..name" is the root of the enclosing
+shortcut whose name starts with name, and ".." the innermost one above the
+current directory.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			sc, err := a.store.GetShortcut(name)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: could not get current directory. Please provide path: fs add <name> <path>\n")
-				os.Exit(1)
+				if !strings.HasPrefix(name, "..") {
+					return err
+				}
+				if sc, err = a.enclosingRoot(name[2:]); err != nil {
+					return err
+				}
 			}
-			path = cwd
-		} else {
-			name = args[0]
-			path = args[1]
-		}
+			// Logged rather than written so the name cache stays valid
+			_ = storage.LogJump(a.dbPath, a.jumpTo(sc.Name, sc.Path))
+
+			// Print the path
+			// called by f(). print path --> jump with cd
+			fmt.Fprintln(a.stdout, sc.Path)
+			return nil
+		},
+	}
+}
 
-		// Expand path (handle ~ and relative paths)
-		absPath, err := expandPath(path)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: invalid path: %v\n", err)
-			os.Exit(1)
-		}
+func (a *app) newAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <name> [path]",
+		Short: "Add a new shortcut",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var path, name string
+			if len(args) == 1 {
+				name = args[0]
+				cwd, err := a.getwd()
+				if err != nil {
+					return fmt.Errorf("could not get current directory. Please provide path: fs add <name> <path>")
+				}
+				path = cwd
+			} else {
+				name = args[0]
+				path = args[1]
+			}
 
-		// Validate path exists
-		if _, err := os.Stat(absPath); os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "Error: path does not exist: %s\n", absPath)
-			os.Exit(1)
-		}
+			// Expand path (handle ~ and relative paths)
+			absPath, err := a.expandPath(path)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
 
-		// Add to database
-		if err := store.AddShortcut(name, absPath); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+			// Validate path exists
+			if _, err := os.Stat(absPath); os.IsNotExist(err) {
+				return fmt.Errorf("path does not exist: %s", absPath)
+			}
+
+			// Add to database
+			if err := a.store.AddShortcut(name, absPath); err != nil {
+				return err
+			}
 
-		fmt.Printf("Added shortcut: %s -> %s\n", name, absPath)
-	},
+			fmt.Fprintf(a.stdout, "Added shortcut: %s -> %s\n", name, absPath)
+			return nil
+		},
+	}
 }
 
-var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all shortcuts",
-	Run: func(cmd *cobra.Command, args []string) {
-		shortcuts, err := store.ListShortcuts()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+func (a *app) newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all shortcuts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shortcuts, err := a.store.ListShortcuts()
+			if err != nil {
+				return err
+			}
 
-		if len(shortcuts) == 0 {
-			fmt.Println("No shortcuts found. Add one with: fs add <name> <path>")
-			return
-		}
+			if len(shortcuts) == 0 {
+				fmt.Fprintln(a.stdout, "No shortcuts found. Add one with: fs add <name> <path>")
+				return nil
+			}
 
-		fmt.Println("Shortcuts:")
-		for _, sc := range shortcuts {
-			tagStr := ""
-			if len(sc.Tags) > 0 {
-				tagStr = fmt.Sprintf(" [%s]", strings.Join(sc.Tags, ", "))
+			fmt.Fprintln(a.stdout, "Shortcuts:")
+			for _, sc := range shortcuts {
+				fmt.Fprintf(a.stdout, "  %s -> %s%s\n", sc.Name, sc.Path, formatTags(sc.Tags))
 			}
-			fmt.Printf("  %s -> %s%s\n", sc.Name, sc.Path, tagStr)
-		}
-	},
+			return nil
+		},
+	}
 }
 
-var deleteCmd = &cobra.Command{
-	Use:     "delete <name>",
-	Aliases: []string{"remove", "rm"},
-	Short:   "Delete a shortcut",
-	Args:    cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		name := args[0]
-
-		if err := store.DeleteShortcut(name); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+func (a *app) newDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "delete <name>...",
+		Aliases: []string{"remove", "rm"},
+		Short:   "Delete shortcuts",
+		Long:    "Delete one or more shortcuts, e.g. fs find --multi --print name | xargs fs rm",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			failed := 0
+			for _, name := range args {
+				if err := a.deleteShortcut(name); err != nil {
+					if len(args) == 1 {
+						return err
+					}
+					fmt.Fprintf(a.stderr, "Error: %v\n", err)
+					failed++
+				}
+			}
 
-		fmt.Printf("Deleted shortcut: %s\n", name)
-	},
+			if failed > 0 {
+				return &exitError{code: 1}
+			}
+			return nil
+		},
+	}
 }
 
-var editPathCmd = &cobra.Command{
-	Use:   "edit-path <name> <new-path>",
-	Short: "Update the path of an existing shortcut (preserves tags)",
-	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
-		name := args[0]
-		newPath := args[1]
+func (a *app) deleteShortcut(name string) error {
+	if err := a.store.DeleteShortcut(name); err != nil {
+		return err
+	}
 
-		// Expand path (handle ~ and relative paths)
-		absPath, err := expandPath(newPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: invalid path: %v\n", err)
-			os.Exit(1)
-		}
+	fmt.Fprintf(a.stdout, "Moved shortcut to trash: %s (restore with: fs trash restore %s)\n", name, name)
+	return nil
+}
 
-		// Validate path exists
-		if _, err := os.Stat(absPath); os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "Error: path does not exist: %s\n", absPath)
-			os.Exit(1)
-		}
+func (a *app) newEditPathCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit-path <name> <new-path>",
+		Short: "Update the path of an existing shortcut (preserves tags)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			newPath := args[1]
+
+			// Expand path (handle ~ and relative paths)
+			absPath, err := a.expandPath(newPath)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
 
-		// Update in database
-		if err := store.UpdateShortcutPath(name, absPath); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+			// Validate path exists
+			if _, err := os.Stat(absPath); os.IsNotExist(err) {
+				return fmt.Errorf("path does not exist: %s", absPath)
+			}
 
-		fmt.Printf("Updated shortcut '%s' to point to: %s\n", name, absPath)
+			// Update in database
+			if err := a.store.UpdateShortcutPath(name, absPath); err != nil {
+				return err
+			}
 
-		// Show current
-		tags, err := store.GetShortcutTags(name)
-		if err == nil && len(tags) > 0 {
-			fmt.Printf("  Tags preserved: %s\n", strings.Join(tags, ", "))
-		}
-	},
+			fmt.Fprintf(a.stdout, "Updated shortcut '%s' to point to: %s\n", name, absPath)
+
+			// Show current
+			tags, err := a.store.GetShortcutTags(name)
+			if err == nil && len(tags) > 0 {
+				fmt.Fprintf(a.stdout, "  Tags preserved: %s\n", strings.Join(tags, ", "))
+			}
+			return nil
+		},
+	}
 }
 
-var editNameCmd = &cobra.Command{
-	Use:   "edit-name <old-name> <new-name>",
-	Short: "Rename an existing shortcut (preserves path and tags)",
-	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
-		oldName := args[0]
-		newName := args[1]
+func (a *app) newEditNameCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit-name <old-name> <new-name>",
+		Short: "Rename an existing shortcut (preserves path and tags)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldName := args[0]
+			newName := args[1]
+
+			// Get current shortcut
+			sc, err := a.store.GetShortcut(oldName)
+			if err != nil {
+				return err
+			}
 
-		// Get current shortcut
-		sc, err := store.GetShortcut(oldName)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+			// Update name in database
+			if err := a.store.UpdateShortcutName(oldName, newName); err != nil {
+				return err
+			}
 
-		// Update name in database
-		if err := store.UpdateShortcutName(oldName, newName); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+			fmt.Fprintf(a.stdout, "Renamed shortcut '%s' to '%s'\n", oldName, newName)
+			fmt.Fprintf(a.stdout, "  Path: %s\n", sc.Path)
 
-		fmt.Printf("Renamed shortcut '%s' to '%s'\n", oldName, newName)
-		fmt.Printf("  Path: %s\n", sc.Path)
+			// Show current tags
+			tags, err := a.store.GetShortcutTags(newName)
+			if err == nil && len(tags) > 0 {
+				fmt.Fprintf(a.stdout, "  Tags: %s\n", strings.Join(tags, ", "))
+			}
+			return nil
+		},
+	}
+}
 
-		// Show current tags
-		tags, err := store.GetShortcutTags(newName)
-		if err == nil && len(tags) > 0 {
-			fmt.Printf("  Tags: %s\n", strings.Join(tags, ", "))
-		}
-	},
+// Expand a path relative to the app's working directory
+func (a *app) expandPath(path string) (string, error) {
+	cwd, err := a.getwd()
+	if err != nil {
+		return "", err
+	}
+	return expandPathFrom(cwd, path)
 }
 
-func expandPath(path string) (string, error) {
+func expandPathFrom(cwd, path string) (string, error) {
 	if strings.TrimSpace(path) == "" {
 		return "", fmt.Errorf("path cannot be empty")
 	}
 
-	// Handle ~ for home directory
-	if strings.HasPrefix(path, "~") {
+	// Handle ~ for home directory. ~user and ~foo are ordinary names here,
+	// not someone else's home
+	if path == "~" || strings.HasPrefix(path, "~/") {
 		home, err := os.UserHomeDir()
 		if err != nil {
 			return "", err
@@ -285,160 +684,315 @@ func expandPath(path string) (string, error) {
 	}
 
 	// Convert to absolute path
-	return filepath.Abs(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	return filepath.Clean(path), nil
 }
 
-var peekCmd = &cobra.Command{
-	Use:     "peek <name>",
-	Aliases: []string{"ls"},
-	Short:   "Preview the contents of a shortcut location",
-	Args:    cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		name := args[0]
+func (a *app) newPeekCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "peek <name>",
+		Aliases: []string{"ls"},
+		Short:   "Preview the contents of a shortcut location",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
 
-		sc, err := store.GetShortcut(name)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+			sc, err := a.store.GetShortcut(name)
+			if err != nil {
+				return err
+			}
 
-		fmt.Printf("Contents of %s (%s):\n\n", name, sc.Path)
+			fmt.Fprintf(a.stdout, "Contents of %s (%s):\n\n", name, sc.Path)
 
-		// Execute ls -lah on the shortcut path
-		lsCmd := exec.Command("ls", "-lah", sc.Path)
-		lsCmd.Stdout = os.Stdout
-		lsCmd.Stderr = os.Stderr
+			// Execute ls -lah on the shortcut path
+			lsCmd := exec.Command("ls", "-lah", sc.Path)
+			lsCmd.Stdout = a.stdout
+			lsCmd.Stderr = a.stderr
 
-		if err := lsCmd.Run(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error running ls: %v\n", err)
-			os.Exit(1)
-		}
-	},
+			if err := lsCmd.Run(); err != nil {
+				return fmt.Errorf("running ls: %w", err)
+			}
+			return nil
+		},
+	}
 }
 
-var tagCmd = &cobra.Command{
-	Use:   "tag <shortcut> <tags...>",
-	Short: "Add tags to a shortcut",
-	Args:  cobra.MinimumNArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
-		shortcutName := args[0]
-		tags := args[1:]
+func (a *app) newTagCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tag <shortcut> <tags...>",
+		Short: "Add tags to a shortcut",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shortcutName := args[0]
+			tags := args[1:]
+
+			if err := a.store.AddTags(shortcutName, tags); err != nil {
+				return err
+			}
 
-		if err := store.AddTags(shortcutName, tags); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+			fmt.Fprintf(a.stdout, "Added tags to %s: %s\n", shortcutName, strings.Join(tags, ", "))
+			return nil
+		},
+	}
+}
 
-		fmt.Printf("Added tags to %s: %s\n", shortcutName, strings.Join(tags, ", "))
-	},
+func (a *app) newUntagCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "untag <shortcut> [tags...]",
+		Short: "Remove tags from a shortcut",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shortcutName := args[0]
+
+			if len(args) == 1 {
+				if err := a.store.RemoveAllTags(shortcutName); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(a.stdout, "Removed all tags from %s (revert with: fs undo)\n", shortcutName)
+				return nil
+			}
+
+			tags := args[1:]
+			if err := a.store.RemoveTags(shortcutName, tags); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Removed tags from %s: %s\n", shortcutName, strings.Join(tags, ", "))
+			return nil
+		},
+	}
 }
 
-var untagCmd = &cobra.Command{
-	Use:   "untag <shortcut> [tags...]",
-	Short: "Remove tags from a shortcut",
-	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		shortcutName := args[0]
+func (a *app) newFindCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "find [query]",
+		Short: "Interactively search and select shortcuts",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := ""
+			if len(args) > 0 {
+				query = args[0]
+			}
 
-		if len(args) == 1 {
-			if err := store.RemoveAllTags(shortcutName); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+			tags, _ := cmd.Flags().GetStringSlice("tag")
+			tagOp := a.cfg.Search.TagOp
+			if cmd.Flags().Changed("tag-op") {
+				tagOp, _ = cmd.Flags().GetString("tag-op")
+			}
+			plain, _ := cmd.Flags().GetBool("plain")
+			multi, _ := cmd.Flags().GetBool("multi")
+			field, _ := cmd.Flags().GetString("print")
+			if field != "path" && field != "name" {
+				return fmt.Errorf("invalid --print value '%s': expected 'path' or 'name'", field)
+			}
+			sortName := a.cfg.Selector.Sort
+			if cmd.Flags().Changed("sort") {
+				sortName, _ = cmd.Flags().GetString("sort")
+			}
+			sortMode, err := ui.ParseSortMode(sortName)
+			if err != nil {
+				return err
+			}
+			group, _ := cmd.Flags().GetBool("group")
+			noMouse, _ := cmd.Flags().GetBool("no-mouse")
+			numbered, _ := cmd.Flags().GetBool("numbered")
+			numbered = numbered || a.numberedSelector()
+			first, _ := cmd.Flags().GetBool("first")
+			best, _ := cmd.Flags().GetBool("best")
+			exact, _ := cmd.Flags().GetBool("exact")
+			index, _ := cmd.Flags().GetInt("index")
+			if cmd.Flags().Changed("index") && index < 1 {
+				return fmt.Errorf("invalid --index value %d: expected 1 or more", index)
+			}
+			if exact && query == "" {
+				return fmt.Errorf("--exact needs a query")
 			}
 
-			fmt.Printf("Removed all tags from %s\n", shortcutName)
-			return
-		}
+			shortcuts, err := a.store.SearchShortcuts(query, tags, tagOp)
+			if err != nil {
+				return err
+			}
+			if exact {
+				shortcuts = exactMatches(shortcuts, query)
+			}
 
-		tags := args[1:]
-		if err := store.RemoveTags(shortcutName, tags); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+			if len(shortcuts) == 0 {
+				fmt.Fprintln(a.stderr, "No shortcuts found")
+				return &exitError{code: 1}
+			}
 
-		fmt.Printf("Removed tags from %s: %s\n", shortcutName, strings.Join(tags, ", "))
-	},
-}
+			// Pick without asking: the nth in the selector's order, or the
+			// best match
+			if first || best || index > 0 {
+				mode := sortMode
+				if best {
+					mode = ui.SortByScore
+				}
+				sorted := ui.Sorted(shortcuts, mode, query, a.now())
+				n := max(index, 1)
+				if n > len(sorted) {
+					return fmt.Errorf("invalid --index value %d: only %d shortcuts found", n, len(sorted))
+				}
+				picked := sorted[n-1 : n]
+				a.printShortcuts(picked, field)
+				a.recordJump(picked, field)
+				return nil
+			}
 
-var findCmd = &cobra.Command{
-	Use:   "find [query]",
-	Short: "Interactively search and select shortcuts",
-	Args:  cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		query := ""
-		if len(args) > 0 {
-			query = args[0]
-		}
+			// If only one result, just print it
+			if len(shortcuts) == 1 {
+				a.printShortcuts(shortcuts, field)
+				a.recordJump(shortcuts, field)
+				return nil
+			}
 
-		tags, _ := cmd.Flags().GetStringSlice("tag")
-		tagOp, _ := cmd.Flags().GetString("tag-op")
-		plain, _ := cmd.Flags().GetBool("plain")
+			theme, keymap, err := a.selectorLook()
+			if err != nil {
+				return err
+			}
+			opts := ui.SelectorOptions{
+				Query:      query,
+				FilterTags: tags,
+				NoColor:    plain || a.cfg.Selector.Theme == "none",
+				Multi:      multi,
+				Store:      a.store,
+				ExpandPath: a.expandPath,
+				Theme:      theme,
+				Keymap:     keymap,
+				Sort:       sortMode,
+				Group:      group,
+				Now:        a.now,
+				TagOp:      tagOp,
+				Mouse:      a.cfg.Selector.Mouse && !noMouse,
+			}
 
-		shortcuts, err := store.SearchShortcuts(query, tags, tagOp)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+			// Without a terminal, or when asked to, list the matches and read a
+			// number or name instead
+			if numbered || !a.interactive() {
+				picked, err := ui.Prompt(shortcuts, opts, a.stdin, a.stderr)
+				if errors.Is(err, ui.ErrNoChoice) {
+					if a.interactive() {
+						return &exitError{code: 1}
+					}
+					fmt.Fprintln(a.stderr, "Several shortcuts match: pick one with --first, --index N or --best")
+					return &exitError{code: exitAmbiguous}
+				}
+				if err != nil {
+					return err
+				}
+				a.printShortcuts(picked, field)
+				a.recordJump(picked, field)
+				return nil
+			}
 
-		if len(shortcuts) == 0 {
-			fmt.Fprintln(os.Stderr, "No shortcuts found")
-			os.Exit(1)
-		}
+			if multi {
+				selected, err := a.selectShortcuts(shortcuts, opts)
+				if err != nil {
+					return &exitError{code: 1}
+				}
+				a.printShortcuts(selected, field)
+				return nil
+			}
 
-		// If only one result, just print path
-		if len(shortcuts) == 1 {
-			fmt.Println(shortcuts[0].Path)
-			return
-		}
+			// Run interactive selector
+			selected, err := a.selectShortcut(shortcuts, opts)
+			if err != nil {
+				return &exitError{code: 1}
+			}
 
-		// Run interactive selector
-		selectedPath, err := ui.RunSelector(shortcuts, ui.SelectorOptions{
-			Query:      query,
-			FilterTags: tags,
-			NoColor:    plain,
-		})
-		if err != nil {
-			os.Exit(1)
-		}
+			// print selected path
+			// called by ff(). print path --> jump with cd
+			picked := []storage.Shortcut{*selected}
+			a.printShortcuts(picked, field)
+			a.recordJump(picked, field)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceP("tag", "t", []string{}, "Filter by tags")
+	cmd.Flags().StringP("tag-op", "o", "or", "Tag filter operator: or|and (default from search.tag_op)")
+	cmd.Flags().BoolP("plain", "p", false, "Disable selector colors")
+	cmd.Flags().BoolP("multi", "m", false, "Select several shortcuts (space to mark, a to mark all)")
+	cmd.Flags().String("print", "path", "What to print for each selection: path|name")
+	cmd.Flags().String("sort", "name", "Selector order: name|path|recent|added|score (default from selector.sort)")
+	cmd.Flags().Bool("group", false, "Group the selector by tag, leaving out the tags filtered on")
+	cmd.Flags().Bool("no-mouse", false, "Leave the mouse to the terminal (default from selector.mouse)")
+	cmd.Flags().Bool("numbered", false, "Ask with a plain numbered list instead of the full-screen picker (default from selector.mode)")
+	cmd.Flags().Bool("first", false, "Pick the first match in --sort order without asking")
+	cmd.Flags().Int("index", 0, "Pick the Nth match in --sort order without asking")
+	cmd.Flags().Bool("best", false, "Pick the best match by score and visits without asking")
+	cmd.Flags().Bool("exact", false, "Only match the shortcut named exactly like the query")
+	cmd.MarkFlagsMutuallyExclusive("first", "index", "best", "multi")
+	return cmd
+}
+
+// Whether selector.mode asks for the numbered list, as auto does on a dumb
+// terminal
+func (a *app) numberedSelector() bool {
+	switch a.cfg.Selector.Mode {
+	case "numbered":
+		return true
+	case "auto":
+		return os.Getenv("TERM") == "dumb"
+	}
+	return false
+}
 
-		// print selected path
-		// called by ff(). print path --> jump with cd
-		fmt.Println(selectedPath)
-	},
+// Shortcuts named query, ignoring case when no name matches exactly
+func exactMatches(shortcuts []storage.Shortcut, query string) []storage.Shortcut {
+	var folded []storage.Shortcut
+	for _, sc := range shortcuts {
+		if sc.Name == query {
+			return []storage.Shortcut{sc}
+		}
+		if strings.EqualFold(sc.Name, query) {
+			folded = append(folded, sc)
+		}
+	}
+	return folded
 }
 
-func init() {
-	findCmd.Flags().StringSliceP("tag", "t", []string{}, "Filter by tags") // Add flags to search before adding it to root
-	findCmd.Flags().StringP("tag-op", "o", "or", "Tag filter operator: or|and")
-	findCmd.Flags().BoolP("plain", "p", false, "Disable selector colors")
+// One line per shortcut, for cd or for piping into xargs
+func (a *app) printShortcuts(shortcuts []storage.Shortcut, field string) {
+	for _, sc := range shortcuts {
+		if field == "name" {
+			fmt.Fprintln(a.stdout, sc.Name)
+			continue
+		}
+		fmt.Fprintln(a.stdout, sc.Path)
+	}
+}
 
-	rootCmd.AddCommand(addCmd)
-	rootCmd.AddCommand(listCmd)
-	rootCmd.AddCommand(deleteCmd)
-	rootCmd.AddCommand(editPathCmd)
-	rootCmd.AddCommand(editNameCmd)
-	rootCmd.AddCommand(initCmd)
-	rootCmd.AddCommand(goCmd)
-	rootCmd.AddCommand(peekCmd)
-	rootCmd.AddCommand(tagCmd)
-	rootCmd.AddCommand(untagCmd)
-	rootCmd.AddCommand(findCmd)
+// Count a printed path as a visit and a jump of the shell's session when it
+// is the one a shell function cd's to
+func (a *app) recordJump(shortcuts []storage.Shortcut, field string) {
+	if field != "path" || len(shortcuts) != 1 {
+		return
+	}
+	_ = a.store.RecordVisit(shortcuts[0].Name)
+	_ = a.store.RecordJump(a.jumpTo(shortcuts[0].Name, shortcuts[0].Path))
 }
 
-func main() {
-	// Initialize storage
-	dbPath := config.GetDBPath()
-	var err error
-	store, err = storage.NewSQLiteStorage(dbPath)
+// Theme and key bindings for the selector and manager, from [selector]
+func (a *app) selectorLook() (*config.Theme, ui.Keymap, error) {
+	theme, err := a.cfg.Selector.ResolveTheme()
+	if err != nil {
+		return nil, ui.Keymap{}, fmt.Errorf("invalid selector theme: %w", err)
+	}
+	keymap, err := ui.NewKeymap(a.cfg.Selector.Keys)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to initialize storage: %v\n", err)
-		os.Exit(1)
+		return nil, ui.Keymap{}, fmt.Errorf("invalid selector keys: %w", err)
 	}
-	defer store.Close()
+	return &theme, keymap, nil
+}
 
-	// Execute command
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+func main() {
+	a := newApp()
+	if a.fastGo(os.Args[1:]) || a.fastPrompt(os.Args[1:]) {
+		return
 	}
+	os.Exit(a.run(os.Args[1:]))
 }
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"bytes"
+	"database/sql"
+	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/internal/ui"
 )
 
 func TestExpandPath_EmptyInput(t *testing.T) {
-	_, err := expandPath("")
+	_, err := expandPathFrom(t.TempDir(), "")
 	if err == nil {
 		t.Fatal("expected error for empty path, got nil")
 	}
@@ -20,9 +29,9 @@ func TestExpandPath_TildeExpansion(t *testing.T) {
 		t.Fatalf("failed to get user home dir: %v", err)
 	}
 
-	got, err := expandPath("~/my-project")
+	got, err := expandPathFrom(t.TempDir(), "~/my-project")
 	if err != nil {
-		t.Fatalf("expandPath returned error: %v", err)
+		t.Fatalf("expandPathFrom returned error: %v", err)
 	}
 
 	want := filepath.Join(home, "my-project")
@@ -32,28 +41,85 @@ func TestExpandPath_TildeExpansion(t *testing.T) {
 }
 
 func TestExpandPath_RelativeToAbsolute(t *testing.T) {
-	oldCwd, err := os.Getwd()
+	tmp := t.TempDir()
+
+	got, err := expandPathFrom(tmp, "./repo")
 	if err != nil {
-		t.Fatalf("failed to get cwd: %v", err)
+		t.Fatalf("expandPathFrom returned error: %v", err)
 	}
-	defer func() {
-		_ = os.Chdir(oldCwd)
-	}()
 
-	tmp := t.TempDir()
-	if err := os.Chdir(tmp); err != nil {
-		t.Fatalf("failed to chdir to temp dir: %v", err)
+	want := filepath.Join(tmp, "repo")
+	if got != want {
+		t.Fatalf("unexpected absolute path. got=%q want=%q", got, want)
 	}
+}
+
+func TestExpandPath_TildeUserIsNotHome(t *testing.T) {
+	cwd := t.TempDir()
 
-	got, err := expandPath("./repo")
+	got, err := expandPathFrom(cwd, "~backup")
 	if err != nil {
-		t.Fatalf("expandPath returned error: %v", err)
+		t.Fatalf("expandPathFrom returned error: %v", err)
 	}
 
-	want := filepath.Join(tmp, "repo")
+	want := filepath.Join(cwd, "~backup")
 	if got != want {
-		t.Fatalf("unexpected absolute path. got=%q want=%q", got, want)
+		t.Fatalf("unexpected path for ~backup. got=%q want=%q", got, want)
+	}
+}
+
+// Properties of the expanded path rather than a second copy of the function
+func FuzzExpandPathFrom(f *testing.F) {
+	for _, seed := range []string{"~", "~/", "~/proj", "~proj", "~user/x", "~/../..", ".", "..", "./a//b/", "/abs/../x", " ", "\t~", "~\x00", "日本/語"} {
+		f.Add(seed)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		f.Fatalf("failed to get user home dir: %v", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		f.Fatalf("failed to get cwd: %v", err)
 	}
+
+	f.Fuzz(func(t *testing.T, path string) {
+		got, err := expandPathFrom(cwd, path)
+		if strings.TrimSpace(path) == "" {
+			if err == nil {
+				t.Fatalf("expected error for blank path %q", path)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("expandPathFrom(%q) returned error: %v", path, err)
+		}
+
+		if !filepath.IsAbs(got) || filepath.Clean(got) != got {
+			t.Fatalf("expandPathFrom(%q) = %q, want a clean absolute path", path, got)
+		}
+
+		// Paths that climb with .. may leave their base, the rest must not
+		climbs := slices.Contains(strings.Split(filepath.ToSlash(path), "/"), "..")
+		switch {
+		case path == "~" || strings.HasPrefix(path, "~/"):
+			if _, under := subpath(home, got); !under && !climbs {
+				t.Fatalf("expandPathFrom(%q) = %q, want a path under home %q", path, got, home)
+			}
+		case strings.HasPrefix(path, "~"):
+			if _, under := subpath(cwd, got); !under && !climbs {
+				t.Fatalf("expandPathFrom(%q) = %q, want ~name kept as a name under %q", path, got, cwd)
+			}
+		default:
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				t.Fatalf("filepath.Abs(%q) returned error: %v", path, err)
+			}
+			if got != abs {
+				t.Fatalf("expandPathFrom(%q) = %q, want %q as filepath.Abs gives", path, got, abs)
+			}
+		}
+	})
 }
 
 func TestRenderInitScript_IncludesErrorHandlingForJumpAndFind(t *testing.T) {
@@ -61,11 +127,11 @@ func TestRenderInitScript_IncludesErrorHandlingForJumpAndFind(t *testing.T) {
 
 	required := []string{
 		"if [ $# -ne 1 ]; then",
-		"path=\"$(fs go \"$1\")\" || return $?",
-		"if [ ! -d \"$path\" ]; then",
-		"path=$(fs find \"$@\" </dev/tty)",
-		"local status=$?",
-		"if [ $status -ne 0 ]; then",
+		"dest=\"$(fs go \"$1\")\" || return $?",
+		"if [ ! -d \"$dest\" ]; then",
+		"dest=$(fs find \"$@\" </dev/tty)",
+		"rc=$?",
+		"if [ $rc -ne 0 ]; then",
 	}
 
 	for _, needle := range required {
@@ -75,6 +141,27 @@ func TestRenderInitScript_IncludesErrorHandlingForJumpAndFind(t *testing.T) {
 	}
 }
 
+func TestRenderFishInitScript_DefinesEveryFunction(t *testing.T) {
+	script := renderFishInitScript("go", "search")
+
+	required := []string{
+		"function go\n",
+		"Usage: go <shortcut>",
+		"set -l dest (fs go $argv[1])\n    or return $status",
+		"function search\n",
+		"set dest (fs find $argv </dev/tty)",
+		"set -gx FS_SESSION $fish_pid",
+		"function fback\n",
+		"function ffwd\n",
+		"function fhist\n",
+	}
+	for _, needle := range required {
+		if !strings.Contains(script, needle) {
+			t.Fatalf("expected fish init script to contain %q", needle)
+		}
+	}
+}
+
 func TestRenderInitScript_UsesCustomFunctionNames(t *testing.T) {
 	script := renderInitScript("go", "search")
 
@@ -90,3 +177,716 @@ func TestRenderInitScript_UsesCustomFunctionNames(t *testing.T) {
 		t.Fatal("expected script to define custom find function")
 	}
 }
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/")
+
+type cliResult struct {
+	stdout string
+	stderr string
+	code   int
+}
+
+// Run fs in-process with captured output and working directory cwd
+func runApp(t *testing.T, cwd string, args ...string) cliResult {
+	t.Helper()
+	return runAppInput(t, cwd, "", args...)
+}
+
+// Run fs with stdin reading input and no terminal
+func runAppInput(t *testing.T, cwd, input string, args ...string) cliResult {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	a := newApp()
+	a.stdin = strings.NewReader(input)
+	a.stdout = &stdout
+	a.stderr = &stderr
+	a.getwd = func() (string, error) { return cwd, nil }
+	a.interactive = func() bool { return false }
+	a.selectShortcut = func(shortcuts []storage.Shortcut, opts ui.SelectorOptions) (*storage.Shortcut, error) {
+		t.Fatal("unexpected interactive selector")
+		return nil, nil
+	}
+	a.selectShortcuts = func(shortcuts []storage.Shortcut, opts ui.SelectorOptions) ([]storage.Shortcut, error) {
+		t.Fatal("unexpected interactive selector")
+		return nil, nil
+	}
+	a.manageShortcuts = func(shortcuts []storage.Shortcut, opts ui.ManagerOptions) (*storage.Shortcut, error) {
+		t.Fatal("unexpected shortcut manager")
+		return nil, nil
+	}
+
+	code := a.run(args)
+	return cliResult{stdout: stdout.String(), stderr: stderr.String(), code: code}
+}
+
+// Run fs and fail the test unless it succeeds, returning stdout
+func runFS(t *testing.T, args ...string) string {
+	t.Helper()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+
+	res := runApp(t, cwd, args...)
+	if res.code != 0 {
+		t.Fatalf("fs %v exited %d: %s", args, res.code, res.stderr)
+	}
+	return res.stdout
+}
+
+// Read a shortcut straight from the database at dbPath
+func getShortcut(t *testing.T, dbPath, name string) *storage.Shortcut {
+	t.Helper()
+
+	s, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open storage: %v", err)
+	}
+	defer s.Close()
+
+	sc, err := s.GetShortcut(name)
+	if err != nil {
+		t.Fatalf("GetShortcut returned error: %v", err)
+	}
+	return sc
+}
+
+// Point the default config and database at an empty temp dir
+func isolateConfig(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("FS_CONFIG", "")
+	t.Setenv("FS_DB", "")
+	t.Setenv("FS_BACKUP_INTERVAL", "0")
+	t.Setenv("FS_SELECTOR_MODE", "")
+	t.Setenv("TERM", "xterm-256color")
+	return home
+}
+
+// transcript runs commands against a temp database and records them like a
+// terminal session, with temp paths and timestamps scrubbed
+type transcript struct {
+	t      *testing.T
+	dir    string
+	cwd    string
+	dbPath string
+	out    strings.Builder
+}
+
+func newTranscript(t *testing.T) *transcript {
+	t.Helper()
+	isolateConfig(t)
+
+	dir := t.TempDir()
+	for _, sub := range []string{"api", "web", "ops"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+	}
+
+	return &transcript{t: t, dir: dir, cwd: dir, dbPath: filepath.Join(dir, "shortcuts.db")}
+}
+
+// Run later commands from dir, relative to the transcript's temp dir
+func (tr *transcript) cd(dir string) {
+	tr.cwd = filepath.Join(tr.dir, dir)
+	fmt.Fprintf(&tr.out, "$ cd %s\n", dir)
+}
+
+func (tr *transcript) run(args ...string) cliResult {
+	tr.t.Helper()
+	return tr.runInput("", args...)
+}
+
+// Run a command without a terminal, with input piped to it
+func (tr *transcript) runInput(input string, args ...string) cliResult {
+	tr.t.Helper()
+
+	res := runAppInput(tr.t, tr.cwd, input, append([]string{"--db", tr.dbPath}, args...)...)
+	if input != "" {
+		args = append(args, "<<<", strings.TrimSpace(input))
+	}
+	tr.record(args, res)
+	return res
+}
+
+// Run a command whose selector picks the named shortcuts, or fails with err.
+// "name:sub" picks the folder sub of the shortcut, as if browsed into.
+func (tr *transcript) runPicking(names []string, err error, args ...string) ui.SelectorOptions {
+	tr.t.Helper()
+
+	var gotOpts ui.SelectorOptions
+	pick := func(shortcuts []storage.Shortcut, opts ui.SelectorOptions) ([]storage.Shortcut, error) {
+		gotOpts = opts
+		if err != nil {
+			return nil, err
+		}
+		var picked []storage.Shortcut
+		for _, name := range names {
+			name, sub, _ := strings.Cut(name, ":")
+			for _, sc := range shortcuts {
+				if sc.Name == name {
+					sc.Path = filepath.Join(sc.Path, sub)
+					picked = append(picked, sc)
+				}
+			}
+		}
+		if len(picked) != len(names) {
+			tr.t.Fatalf("selector was not offered all of %v", names)
+		}
+		return picked, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	a := newApp()
+	a.stdout, a.stderr = &stdout, &stderr
+	a.getwd = func() (string, error) { return tr.cwd, nil }
+	a.interactive = func() bool { return true }
+	a.selectShortcut = func(shortcuts []storage.Shortcut, opts ui.SelectorOptions) (*storage.Shortcut, error) {
+		picked, err := pick(shortcuts, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &picked[0], nil
+	}
+	a.selectShortcuts = pick
+
+	code := a.run(append([]string{"--db", tr.dbPath}, args...))
+	tr.record(args, cliResult{stdout: stdout.String(), stderr: stderr.String(), code: code})
+	return gotOpts
+}
+
+// Run a command whose manager picks the named shortcut, or quits if name is ""
+func (tr *transcript) runManaging(name string, args ...string) ui.ManagerOptions {
+	tr.t.Helper()
+
+	var gotOpts ui.ManagerOptions
+	var stdout, stderr bytes.Buffer
+	a := newApp()
+	a.stdout, a.stderr = &stdout, &stderr
+	a.getwd = func() (string, error) { return tr.cwd, nil }
+	a.manageShortcuts = func(shortcuts []storage.Shortcut, opts ui.ManagerOptions) (*storage.Shortcut, error) {
+		gotOpts = opts
+		for _, sc := range shortcuts {
+			if sc.Name == name {
+				return &sc, nil
+			}
+		}
+		if name != "" {
+			tr.t.Fatalf("manager was not offered %s", name)
+		}
+		return nil, nil
+	}
+
+	code := a.run(append([]string{"--db", tr.dbPath}, args...))
+	tr.record(args, cliResult{stdout: stdout.String(), stderr: stderr.String(), code: code})
+	return gotOpts
+}
+
+func (tr *transcript) record(args []string, res cliResult) {
+	fmt.Fprintf(&tr.out, "$ fs %s\n", strings.Join(args, " "))
+	tr.out.WriteString(res.stdout)
+	for _, line := range strings.SplitAfter(res.stderr, "\n") {
+		if line != "" {
+			fmt.Fprintf(&tr.out, "[stderr] %s", line)
+		}
+	}
+	if res.code != 0 {
+		fmt.Fprintf(&tr.out, "[exit %d]\n", res.code)
+	}
+	tr.out.WriteString("\n")
+}
+
+var (
+	scrubTimestamp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}(:\d{2})?(\.\d+)?(Z|[+-]\d{2}:\d{2})?`)
+	scrubBackupID  = regexp.MustCompile(`\d{8}-\d{6}(-\d+)?`)
+)
+
+// Compare the transcript against testdata/<name>.golden
+func (tr *transcript) assertGolden(name string) {
+	tr.t.Helper()
+
+	got := strings.ReplaceAll(tr.out.String(), tr.dir, "$TMP")
+	got = scrubTimestamp.ReplaceAllString(got, "<time>")
+	got = scrubBackupID.ReplaceAllString(got, "<id>")
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			tr.t.Fatalf("failed to create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			tr.t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		tr.t.Fatalf("failed to read golden file (run go test ./cmd/fs -update): %v", err)
+	}
+	if got != string(want) {
+		tr.t.Fatalf("output does not match %s (run go test ./cmd/fs -update to accept)\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+func TestCLI_ShortcutLifecycle(t *testing.T) {
+	tr := newTranscript(t)
+
+	tr.run("list")
+	tr.run("add", "api", "api")
+	tr.run("add", "web", tr.dir+"/web")
+	tr.run("tag", "api", "go", "proj")
+	tr.run("tag", "web", "proj", "frontend")
+	tr.run("list")
+	tr.run("go", "api")
+	tr.run("edit-path", "api", "ops")
+	tr.run("edit-name", "api", "backend")
+	tr.run("untag", "web", "frontend")
+	tr.run("untag", "backend")
+	tr.run("list")
+
+	tr.assertGolden("lifecycle")
+}
+
+func TestCLI_TrashUndoAndHistory(t *testing.T) {
+	tr := newTranscript(t)
+
+	tr.run("add", "api", "api")
+	tr.run("tag", "api", "go")
+	tr.run("rm", "api")
+	tr.run("trash", "list")
+	tr.run("trash", "restore", "api")
+	tr.run("untag", "api")
+	tr.run("undo")
+	tr.run("list")
+	tr.run("undo")
+	tr.run("redo")
+	tr.run("history")
+	tr.run("rm", "api")
+	tr.run("trash", "empty")
+	tr.run("trash", "list")
+	tr.run("undo")
+	tr.run("list")
+
+	tr.assertGolden("trash_undo_history")
+}
+
+func TestCLI_TrashListHidesExpired(t *testing.T) {
+	isolateConfig(t)
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+
+	for _, name := range []string{"old", "new"} {
+		runFS(t, "--db", dbPath, "add", name, t.TempDir())
+		runFS(t, "--db", dbPath, "rm", name)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("UPDATE trash SET deleted_at = datetime('now', '-40 days') WHERE name = 'old'"); err != nil {
+		t.Fatalf("failed to age trash entry: %v", err)
+	}
+
+	out := runFS(t, "--db", dbPath, "trash", "list")
+	if strings.Contains(out, "old") || !strings.Contains(out, "new") {
+		t.Fatalf("expected only 'new' past the 30 day retention, got:\n%s", out)
+	}
+
+	cwd, _ := os.Getwd()
+	if res := runApp(t, cwd, "--db", dbPath, "trash", "restore", "old"); res.code == 0 {
+		t.Fatalf("expected restoring an expired shortcut to fail, got: %s", res.stdout)
+	}
+}
+
+// A name printed for a browsed folder is the shortcut's, so it can be fed
+// back to fs go or fs rm
+func TestCLI_FindPrintNameOfBrowsedFolder(t *testing.T) {
+	tr := newTranscript(t)
+
+	tr.run("add", "web", "web")
+	tr.runPicking([]string{"web:src/ui"}, nil, "find", "--print", "name")
+	if out := tr.out.String(); !strings.HasSuffix(out, "$ fs find --print name\nweb\n\n") {
+		t.Fatalf("expected the plain shortcut name, got:\n%s", out)
+	}
+
+	if res := tr.run("go", "web"); res.code != 0 {
+		t.Fatalf("fs go web exited %d: %s", res.code, res.stderr)
+	}
+}
+
+func TestCLI_Errors(t *testing.T) {
+	tr := newTranscript(t)
+
+	tr.run("go", "missing")
+	tr.run("add", "api", "does-not-exist")
+	tr.run("add", "api", "api")
+	tr.run("add", "api", "web")
+	tr.run("tag", "missing", "go")
+	tr.run("edit-name", "api", "api")
+	tr.run("redo")
+	tr.run("trash", "restore", "missing")
+	tr.run("backup", "restore", "nope")
+
+	tr.assertGolden("errors")
+}
+
+func TestCLI_Find(t *testing.T) {
+	tr := newTranscript(t)
+
+	tr.run("add", "api", "api")
+	tr.run("add", "web", "web")
+	tr.run("tag", "api", "go")
+	tr.run("find", "nothing")
+	tr.run("find", "-t", "go")
+
+	opts := tr.runPicking([]string{"web"}, nil, "find", "--tag-op", "and", "-p")
+	if !opts.NoColor {
+		t.Fatal("expected --plain to disable selector colors")
+	}
+	tr.runPicking(nil, fmt.Errorf("no selection made"), "find", "--tag-op", "and", "-p")
+	tr.runPicking([]string{"web"}, nil, "find", "--print", "name")
+	tr.runPicking([]string{"web:src/ui"}, nil, "find", "--print", "name")
+	tr.run("find", "--print", "size")
+	opts = tr.runPicking([]string{"web"}, nil, "find", "--sort", "recent", "--group")
+	if opts.Sort != ui.SortByRecent || !opts.Group {
+		t.Fatalf("expected --sort and --group to reach the selector, got %v %v", opts.Sort, opts.Group)
+	}
+	if !opts.Mouse {
+		t.Fatal("expected the mouse to be on by default")
+	}
+	opts = tr.runPicking([]string{"web"}, nil, "find", "--no-mouse", "-o", "all")
+	if opts.Mouse || opts.TagOp != "all" {
+		t.Fatalf("expected --no-mouse and the tag operator to reach the selector, got %v %q", opts.Mouse, opts.TagOp)
+	}
+	tr.run("find", "--sort", "size")
+
+	tr.run("add", "docs", "ops")
+	opts = tr.runPicking([]string{"api", "docs"}, nil, "find", "--multi", "--print", "name")
+	if !opts.Multi {
+		t.Fatal("expected --multi to open the multi-select selector")
+	}
+	tr.runPicking([]string{"web", "api"}, nil, "find", "-m")
+	tr.runPicking(nil, fmt.Errorf("no selection made"), "find", "-m")
+	tr.run("rm", "api", "docs", "missing")
+	tr.run("list")
+
+	tr.assertGolden("find")
+}
+
+func TestCLI_FindWithoutTerminal(t *testing.T) {
+	tr := newTranscript(t)
+
+	tr.run("add", "api", "api")
+	tr.run("add", "apidocs", "web")
+	tr.run("add", "ops", "ops")
+	tr.run("go", "apidocs")
+
+	tr.run("find", "--best")
+	tr.run("find", "api", "--first")
+	tr.run("find", "--index", "3")
+	tr.run("find", "--index", "4")
+	tr.run("find", "--index", "0")
+	tr.run("find", "api", "--best", "--sort", "path")
+	tr.run("find", "--sort", "path", "--first", "--print", "name")
+	tr.run("find", "API", "--exact")
+	tr.run("find", "apid", "--exact")
+	tr.run("find", "--exact")
+	tr.run("find", "--first", "--best")
+
+	// No terminal and nothing piped in: a distinct status for scripts
+	res := tr.run("find", "api")
+	if res.code != exitAmbiguous {
+		t.Fatalf("expected exit %d, got %d", exitAmbiguous, res.code)
+	}
+	tr.runInput("2\n", "find", "api")
+	tr.runInput("5\n", "find", "api")
+	tr.runInput("1 2\n", "find", "api")
+	tr.runInput("3, 1\n", "find", "-m", "--print", "name")
+	tr.runInput("apid\n", "find", "api")
+	tr.runInput("ap\n", "find", "api")
+	tr.runInput("o a\n", "find", "-m", "--print", "name")
+
+	tr.assertGolden("find_no_terminal")
+}
+
+func TestCLI_BackForwardAndHist(t *testing.T) {
+	tr := newTranscript(t)
+	t.Setenv("FS_SESSION", "42")
+
+	tr.run("add", "api", "api")
+	tr.run("add", "web", "web")
+	tr.run("hist")
+	tr.run("go", "api")
+	tr.run("go", "web")
+	tr.run("find", "ops")
+	tr.run("add", "ops", "ops")
+	tr.run("find", "ops")
+	tr.run("hist", "--list")
+
+	tr.run("back")
+	tr.run("back", "5")
+	tr.run("back")
+	tr.run("forward")
+	tr.run("forward", "0")
+	tr.run("hist", "-l")
+	tr.runInput("web\n", "hist")
+	tr.run("forward")
+
+	t.Setenv("FS_SESSION", "43")
+	tr.run("back")
+	tr.run("hist")
+
+	tr.assertGolden("back_forward_hist")
+}
+
+func TestCLI_Which(t *testing.T) {
+	tr := newTranscript(t)
+	for _, dir := range []string{"api/internal/ui", "api-old"} {
+		if err := os.MkdirAll(filepath.Join(tr.dir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tr.run("add", "api", "api")
+	tr.run("add", "backend", "api")
+	tr.run("add", "ui", "api/internal/ui")
+	tr.run("tag", "api", "go")
+
+	tr.run("which", "api/internal")
+	tr.run("which", "api/internal/ui/testdata")
+	tr.run("which", "--all", "api/internal/ui")
+	tr.run("which", "--json", "api/internal")
+	tr.run("which", "api-old")
+	tr.run("which", "--json", "web")
+
+	tr.cd("api/internal/ui/testdata")
+	tr.run("which")
+	tr.run("go", "..")
+	tr.run("go", "..b")
+	tr.run("go", "..web")
+	tr.cd("api/internal/ui")
+	tr.run("go", "..")
+	tr.run("go", "..ui")
+	tr.cd("api")
+	tr.run("go", "..")
+
+	tr.assertGolden("which")
+}
+
+func TestCLI_Prompt(t *testing.T) {
+	tr := newTranscript(t)
+	if err := os.MkdirAll(filepath.Join(tr.dir, "api", "internal", "ui"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tr.run("add", "api", "api")
+	tr.run("tag", "api", "go", "work")
+
+	tr.run("prompt")
+	tr.run("prompt", "api/internal/ui")
+	tr.run("prompt", "--format", "({name}{ #tags})", "api/internal")
+	tr.run("prompt", "--format", "{branch}", "api")
+	tr.cd("api/internal")
+	tr.run("prompt")
+
+	tr.run("init", "--prompt", "--shell", "tcsh")
+	tr.run("init", "--shell", "starship")
+
+	tr.assertGolden("prompt")
+}
+
+func TestCLI_FindNumbered(t *testing.T) {
+	tr := newTranscript(t)
+	tr.run("add", "api", "api")
+	tr.run("add", "web", "web")
+
+	// A terminal that can't draw the picker gets the numbered list
+	t.Setenv("TERM", "dumb")
+	tr.runInput("we\n", "find")
+
+	t.Setenv("TERM", "xterm-256color")
+	tr.runPicking([]string{"api"}, nil, "find")
+	t.Setenv("FS_SELECTOR_MODE", "numbered")
+	tr.runInput("1\n", "find")
+	t.Setenv("FS_SELECTOR_MODE", "full")
+	tr.runInput("2\n", "find", "--numbered")
+
+	tr.assertGolden("find_numbered")
+}
+
+func TestCLI_UI(t *testing.T) {
+	tr := newTranscript(t)
+
+	tr.run("ui")
+	tr.run("add", "api", "api")
+	tr.run("add", "web", "web")
+
+	opts := tr.runManaging("web", "ui", "--plain")
+	if !opts.NoColor || opts.Store == nil {
+		t.Fatalf("expected a plain manager with a store, got %+v", opts)
+	}
+	tr.runManaging("", "ui")
+
+	tr.assertGolden("ui")
+
+	if sc := getShortcut(t, tr.dbPath, "web"); sc.Visits != 1 {
+		t.Fatalf("expected the pick to count as a visit, got %d", sc.Visits)
+	}
+}
+
+func TestCLI_Config(t *testing.T) {
+	tr := newTranscript(t)
+	t.Setenv("FS_CONFIG", filepath.Join(tr.dir, "config.toml"))
+
+	tr.run("config", "get", "search.tag_op")
+	tr.run("config", "set", "search.tag_op", "and")
+	tr.run("config", "set", "output.format", "yaml")
+	tr.run("config", "get", "search.tag_op")
+
+	if err := os.WriteFile(filepath.Join(tr.dir, "config.toml"), []byte("[search]\ntag_op = \"xor\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	tr.run("list")
+	tr.run("config", "path")
+
+	tr.assertGolden("config")
+}
+
+func TestCLI_UsageErrorsShowUsage(t *testing.T) {
+	isolateConfig(t)
+
+	res := runApp(t, t.TempDir(), "--db", filepath.Join(t.TempDir(), "db"), "go")
+	if res.code != 1 {
+		t.Fatalf("expected exit 1, got %d", res.code)
+	}
+	if !strings.Contains(res.stderr, "Usage:") || !strings.Contains(res.stderr, "accepts 1 arg(s)") {
+		t.Fatalf("expected usage and arg error on stderr, got %q", res.stderr)
+	}
+}
+
+func TestDBFlag_UsesAlternateDatabase(t *testing.T) {
+	home := isolateConfig(t)
+	dbPath := filepath.Join(t.TempDir(), "sandbox.db")
+	target := t.TempDir()
+
+	runFS(t, "--db", dbPath, "add", "proj", target)
+	runFS(t, "--db", dbPath, "tag", "proj", "go", "work")
+
+	out := runFS(t, "--db", dbPath, "go", "proj")
+	if strings.TrimSpace(out) != target {
+		t.Fatalf("expected go to print %q, got %q", target, out)
+	}
+
+	out = runFS(t, "--db", dbPath, "list")
+	if !strings.Contains(out, "proj -> "+target+" [go, work]") {
+		t.Fatalf("expected list to show tagged shortcut, got %q", out)
+	}
+
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Fatalf("expected database at --db path: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "fs", "shortcuts.db")); !os.IsNotExist(err) {
+		t.Fatalf("expected default database to be left alone, stat err=%v", err)
+	}
+}
+
+func TestDBFlag_OverridesEnv(t *testing.T) {
+	isolateConfig(t)
+	envDB := filepath.Join(t.TempDir(), "env.db")
+	flagDB := filepath.Join(t.TempDir(), "flag.db")
+	t.Setenv("FS_DB", envDB)
+
+	runFS(t, "--db", flagDB, "add", "proj", t.TempDir())
+
+	if _, err := os.Stat(envDB); !os.IsNotExist(err) {
+		t.Fatalf("expected FS_DB to be overridden by --db, stat err=%v", err)
+	}
+	if _, err := os.Stat(flagDB); err != nil {
+		t.Fatalf("expected database at --db path: %v", err)
+	}
+}
+
+func TestFSDBEnv_ExpandsTilde(t *testing.T) {
+	isolateConfig(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("FS_DB", "~/team/shortcuts.db")
+
+	runFS(t, "add", "proj", t.TempDir())
+
+	if _, err := os.Stat(filepath.Join(home, "team", "shortcuts.db")); err != nil {
+		t.Fatalf("expected FS_DB with ~ to be expanded: %v", err)
+	}
+}
+
+func TestSetup_OnlyWritesRunMaintenance(t *testing.T) {
+	isolateConfig(t)
+	t.Setenv("FS_BACKUP_INTERVAL", "1h")
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	backups := filepath.Join(filepath.Dir(dbPath), "backups")
+
+	runFS(t, "--db", dbPath, "list")
+	runFS(t, "--db", dbPath, "prompt")
+	if _, err := os.Stat(backups); !os.IsNotExist(err) {
+		t.Fatalf("expected lookups to skip the automatic backup, stat err=%v", err)
+	}
+
+	runFS(t, "--db", dbPath, "add", "proj", t.TempDir())
+	if _, err := os.Stat(backups); err != nil {
+		t.Fatalf("expected add to take the automatic backup: %v", err)
+	}
+}
+
+func TestCLI_BackupRestoreKeepsRotation(t *testing.T) {
+	isolateConfig(t)
+	t.Setenv("FS_BACKUP_KEEP", "2")
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	dir := storage.DefaultBackupDir(dbPath)
+
+	runFS(t, "--db", dbPath, "add", "proj", t.TempDir())
+	for i := 0; i < 2; i++ {
+		runFS(t, "--db", dbPath, "backup", "create")
+	}
+	backups, err := storage.ListBackups(dir)
+	if err != nil || len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %d (err=%v)", len(backups), err)
+	}
+
+	runFS(t, "--db", dbPath, "backup", "restore", backups[1].ID)
+
+	backups, err = storage.ListBackups(dir)
+	if err != nil {
+		t.Fatalf("ListBackups returned error: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected the backup taken before restoring to be rotated in, got %d backups", len(backups))
+	}
+}
+
+func TestConfigDBPath_ExpandsTilde(t *testing.T) {
+	isolateConfig(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("[db]\npath = \"~/team/shortcuts.db\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	t.Setenv("FS_CONFIG", configPath)
+
+	cwd := t.TempDir()
+	if res := runApp(t, cwd, "add", "proj", t.TempDir()); res.code != 0 {
+		t.Fatalf("add exited %d: %s", res.code, res.stderr)
+	}
+
+	if _, err := os.Stat(filepath.Join(home, "team", "shortcuts.db")); err != nil {
+		t.Fatalf("expected db.path with ~ to be expanded: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cwd, "~")); !os.IsNotExist(err) {
+		t.Fatalf("expected no literal ~ directory, stat err=%v", err)
+	}
+}
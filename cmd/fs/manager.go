@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func (a *app) newUICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ui",
+		Short: "Browse and manage shortcuts full screen",
+		Long: `Open a full-screen manager with a tag sidebar, a sortable table of
+shortcuts and a detail panel. Rows can be filtered, renamed, retagged and
+deleted one at a time or in bulk. The path picked with enter is printed,
+e.g. dest=$(fs ui) && cd "$dest"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plain, _ := cmd.Flags().GetBool("plain")
+
+			shortcuts, err := a.store.ListShortcuts()
+			if err != nil {
+				return err
+			}
+			if len(shortcuts) == 0 {
+				fmt.Fprintln(a.stderr, "No shortcuts found. Add one with: fs add <name> <path>")
+				return &exitError{code: 1}
+			}
+
+			theme, keymap, err := a.selectorLook()
+			if err != nil {
+				return err
+			}
+			selected, err := a.manageShortcuts(shortcuts, ui.ManagerOptions{
+				NoColor:    plain || a.cfg.Selector.Theme == "none",
+				Store:      a.store,
+				ExpandPath: a.expandPath,
+				Theme:      theme,
+				Keymap:     keymap,
+			})
+			if err != nil {
+				return err
+			}
+			if selected == nil {
+				return &exitError{code: 1}
+			}
+
+			picked := []storage.Shortcut{*selected}
+			a.printShortcuts(picked, "path")
+			a.recordJump(picked, "path")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolP("plain", "p", false, "Disable colors")
+	return cmd
+}
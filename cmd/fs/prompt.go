@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// `fs prompt` runs before every prompt, so it must never hold one up. It
+// reads the name cache when it can (see fastPrompt), and all of its work
+// runs against prompt.timeout: the git check gets most of it and is dropped
+// when it runs late, and past the whole budget nothing is printed at all.
+
+func (a *app) newPromptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompt [path]",
+		Short: "Print a shell prompt segment for the shortcut you're in",
+		Long: `Print a short segment such as "[api:internal/ui*]" when path (default:
+the current directory) is inside a shortcut, and nothing otherwise. The
+segment is set by prompt.format. Hook it into your shell with:
+fs init --prompt`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := a.cfg.Prompt
+			if cmd.Flags().Changed("format") {
+				cfg.Format, _ = cmd.Flags().GetString("format")
+			}
+			parts, err := config.ParsePromptFormat(cfg.Format)
+			if err != nil {
+				return fmt.Errorf("invalid format: %w", err)
+			}
+
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			dir, err = a.expandPath(dir)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+
+			shortcuts, err := a.store.ListShortcuts()
+			if err != nil {
+				return err
+			}
+
+			a.printSegment(promptSegment(parts, cfg, shortcuts, dir))
+			return nil
+		},
+	}
+
+	cmd.Flags().String("format", "", "Segment format (default from prompt.format)")
+	return cmd
+}
+
+func (a *app) printSegment(segment string) {
+	if segment != "" {
+		fmt.Fprintln(a.stdout, segment)
+	}
+}
+
+// The segment for dir, or "" outside every shortcut or past the budget
+func promptSegment(parts []config.PromptPart, cfg config.PromptConfig, shortcuts []storage.Shortcut, dir string) string {
+	budget := time.Duration(cfg.Timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+
+	done := make(chan string, 1)
+	go func() {
+		// The git check must finish in time for the rest to be printed
+		gitCtx, gitCancel := context.WithTimeout(ctx, budget*3/4)
+		defer gitCancel()
+		done <- renderSegment(gitCtx, parts, cfg, shortcuts, dir)
+	}()
+
+	select {
+	case segment := <-done:
+		return segment
+	case <-ctx.Done():
+		return ""
+	}
+}
+
+func renderSegment(ctx context.Context, parts []config.PromptPart, cfg config.PromptConfig, shortcuts []storage.Shortcut, dir string) string {
+	found := innermost(enclosingShortcuts(shortcuts, dir))
+	if len(found) == 0 {
+		return ""
+	}
+	e := found[0]
+
+	var b strings.Builder
+	for _, part := range parts {
+		var value string
+		switch part.Field {
+		case "":
+			b.WriteString(part.Text)
+			continue
+		case "name":
+			value = e.Name
+		case "subpath":
+			value = e.Rel
+		case "tags":
+			value = strings.Join(e.Tags, ",")
+		case "dirty":
+			if gitDirty(ctx, dir) {
+				value = cfg.Dirty
+			}
+		}
+		if value != "" {
+			b.WriteString(part.Before + value + part.After)
+		}
+	}
+	return b.String()
+}
+
+// Whether the git work tree holding dir has uncommitted changes to tracked
+// files. Untracked files are skipped, they make git status much slower.
+func gitDirty(ctx context.Context, dir string) bool {
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "status", "--porcelain", "--untracked-files=no", "--ignore-submodules")
+	// Don't take the index lock from under a git command in another shell
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
+	// Stop waiting on output from anything git started once it's killed
+	cmd.WaitDelay = 10 * time.Millisecond
+	out, err := cmd.Output()
+	return err == nil && len(bytes.TrimSpace(out)) > 0
+}
+
+// Prompt hooks for `fs init --prompt`, by shell
+func renderPromptScript(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashPromptScript, nil
+	case "zsh":
+		return zshPromptScript, nil
+	case "fish":
+		return fishPromptScript, nil
+	case "starship":
+		return starshipPromptModule, nil
+	}
+	return "", fmt.Errorf("unknown shell %q: expected bash, zsh, fish or starship", shell)
+}
+
+// Runs last in PROMPT_COMMAND and keeps $? for PS1
+const bashPromptScript = `
+# fs prompt segment, e.g. [api:internal/ui*]
+
+__fs_prompt() {
+    local rc=$?
+    FS_PROMPT="$(fs prompt 2>/dev/null)"
+    return $rc
+}
+
+case "${PROMPT_COMMAND[*]}" in
+    *__fs_prompt*) ;;
+    *) PROMPT_COMMAND="${PROMPT_COMMAND:+$PROMPT_COMMAND
+}__fs_prompt" ;;
+esac
+
+# Shown in front of the prompt, move ${FS_PROMPT} around PS1 to taste
+case "$PS1" in
+    *FS_PROMPT*) ;;
+    *) PS1='${FS_PROMPT:+$FS_PROMPT }'"$PS1" ;;
+esac
+`
+
+// %% keeps a % in a folder name from being read as a prompt escape
+const zshPromptScript = `
+# fs prompt segment, e.g. [api:internal/ui*]
+
+__fs_prompt() {
+    FS_PROMPT="$(fs prompt 2>/dev/null)"
+}
+
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd __fs_prompt
+setopt prompt_subst
+
+# Shown in front of the prompt, move ${FS_PROMPT} around PROMPT to taste
+case "$PROMPT" in
+    *FS_PROMPT*) ;;
+    *) PROMPT='${FS_PROMPT:+${FS_PROMPT//\%/%%} }'"$PROMPT" ;;
+esac
+`
+
+const fishPromptScript = `
+# fs prompt segment, e.g. [api:internal/ui*]
+
+function __fs_prompt --on-event fish_prompt
+    set -g FS_PROMPT (fs prompt 2>/dev/null)
+end
+
+# Shown as the right prompt unless you have one: then add
+# echo -n $FS_PROMPT to your fish_prompt or fish_right_prompt
+if not functions -q fish_right_prompt
+    function fish_right_prompt
+        echo -n $FS_PROMPT
+    end
+end
+`
+
+const starshipPromptModule = `
+# fs prompt segment for starship, e.g. [api:internal/ui*]
+# Add to ~/.config/starship.toml. Custom modules show where $custom is in
+# your format, which the default format has.
+
+[custom.fs]
+description = "The fs shortcut you're in"
+command = "fs prompt"
+when = true
+shell = ["sh"]
+format = "[$output]($style) "
+style = "bold blue"
+`
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/pkg/config"
+)
+
+func TestPrompt_DirtyGitWorkTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=fs", "-c", "user.email=fs@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "go.mod")
+	git("commit", "-qm", "init")
+
+	cfg := config.Default().Prompt
+	cfg.Timeout = config.Duration(5 * time.Second)
+	parts, _ := config.ParsePromptFormat(cfg.Format)
+	shortcuts := []storage.Shortcut{{Name: "api", Path: dir}}
+
+	if got := promptSegment(parts, cfg, shortcuts, dir); got != "[api]" {
+		t.Fatalf("expected a clean work tree, got %q", got)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := promptSegment(parts, cfg, shortcuts, dir); got != "[api*]" {
+		t.Fatalf("expected a dirty work tree, got %q", got)
+	}
+}
+
+func TestPrompt_KeepsToTheTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script standing in for git")
+	}
+	// A git that hangs
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte("#!/bin/sh\nsleep 5\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := config.Default().Prompt
+	cfg.Timeout = config.Duration(200 * time.Millisecond)
+	parts, _ := config.ParsePromptFormat(cfg.Format)
+	dir := t.TempDir()
+
+	start := time.Now()
+	got := promptSegment(parts, cfg, []storage.Shortcut{{Name: "api", Path: dir}}, dir)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the prompt within its timeout, took %v", elapsed)
+	}
+	// Usually the git check is dropped, on a loaded machine the whole segment
+	if got != "[api]" && got != "" {
+		t.Fatalf("expected no dirty marker, got %q", got)
+	}
+}
+
+func TestRenderPromptScript(t *testing.T) {
+	for shell, want := range map[string]string{
+		"bash":     "PROMPT_COMMAND=",
+		"zsh":      "add-zsh-hook precmd __fs_prompt",
+		"fish":     "--on-event fish_prompt",
+		"starship": "[custom.fs]",
+	} {
+		script, err := renderPromptScript(shell)
+		if err != nil || !strings.Contains(script, want) || !strings.Contains(script, "fs prompt") {
+			t.Errorf("expected the %s hook to contain %q, got %v:\n%s", shell, want, err, script)
+		}
+	}
+}
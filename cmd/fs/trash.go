@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func (a *app) newTrashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "Manage deleted shortcuts",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List deleted shortcuts",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trashed, err := a.store.ListTrash()
+			if err != nil {
+				return err
+			}
+
+			if len(trashed) == 0 {
+				fmt.Fprintln(a.stdout, "Trash is empty")
+				return nil
+			}
+
+			fmt.Fprintln(a.stdout, "Trash:")
+			for _, sc := range trashed {
+				fmt.Fprintf(a.stdout, "  %s -> %s%s  (deleted %s)\n", sc.Name, sc.Path, formatTags(sc.Tags), sc.DeletedAt.Local().Format("2006-01-02 15:04"))
+			}
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "restore <name>",
+		Short: "Restore a deleted shortcut with its tags",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			if err := a.store.RestoreShortcut(name); err != nil {
+				return err
+			}
+
+			sc, err := a.store.GetShortcut(name)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Restored shortcut: %s -> %s\n", sc.Name, sc.Path)
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "empty",
+		Short: "Permanently delete everything in the trash",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := a.store.EmptyTrash()
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Permanently deleted %d shortcut(s)\n", n)
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+// Drop trashed shortcuts past the retention period. Runs before the
+// commands in upkeepCommands, so failures only warn.
+func (a *app) purgeTrash() {
+	retention := time.Duration(a.cfg.Trash.Retention)
+	if retention == 0 {
+		return
+	}
+
+	if _, err := a.store.PurgeTrash(retention); err != nil {
+		fmt.Fprintf(a.stderr, "Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !notui
+
+package main
+
+import "github.com/mikul1999-pixel/fs/internal/ui"
+
+// The selector and manager run in this binary. See tui_helper.go for the
+// light build that leaves them out.
+var (
+	runSelector      = ui.RunSelector
+	runMultiSelector = ui.RunMultiSelector
+	runManager       = ui.RunManager
+)
@@ -0,0 +1,66 @@
+//go:build notui
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/internal/ui"
+)
+
+// tuiHelper is the full build of fs, installed next to the light one
+const tuiHelper = "fs-tui"
+
+// Built with -tags notui, fs doesn't link the TUI packages, so `fs go` and
+// the prompt skip their init. A command that needs the selector or manager
+// is run again by fs-tui, which takes over the terminal and exits with its
+// status.
+var (
+	runSelector = func([]storage.Shortcut, ui.SelectorOptions) (*storage.Shortcut, error) {
+		return nil, runTUIHelper()
+	}
+	runMultiSelector = func([]storage.Shortcut, ui.SelectorOptions) ([]storage.Shortcut, error) {
+		return nil, runTUIHelper()
+	}
+	runManager = func([]storage.Shortcut, ui.ManagerOptions) (*storage.Shortcut, error) {
+		return nil, runTUIHelper()
+	}
+)
+
+// Hand the whole command to fs-tui. Returns only if it couldn't be started;
+// the callers exit quietly on a selector error, so it is reported here.
+func runTUIHelper() error {
+	path, err := findTUIHelper()
+	if err == nil {
+		cmd := exec.Command(path, os.Args[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		err = cmd.Run()
+		var exitErr *exec.ExitError
+		if err == nil || errors.As(err, &exitErr) {
+			os.Exit(cmd.ProcessState.ExitCode())
+		}
+		err = fmt.Errorf("failed to run %s: %w", path, err)
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	return err
+}
+
+// fs-tui next to this binary, or else on PATH
+func findTUIHelper() (string, error) {
+	if exe, err := os.Executable(); err == nil {
+		path := filepath.Join(filepath.Dir(exe), tuiHelper)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	path, err := exec.LookPath(tuiHelper)
+	if err != nil {
+		return "", fmt.Errorf("%s is needed for the selector and manager; install it next to fs with make install", tuiHelper)
+	}
+	return path, nil
+}
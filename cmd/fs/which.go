@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// A shortcut whose folder holds some path, and where the path sits in it
+type enclosing struct {
+	storage.Shortcut
+	Rel string // "" at the shortcut's own folder, else slash separated
+}
+
+// Shortcuts whose folder is path or one of its parents, innermost first.
+// Paths compare by whole components, so /src/api-old is not under /src/api.
+// When nothing matches, path is retried with its symlinks resolved.
+func enclosingShortcuts(shortcuts []storage.Shortcut, path string) []enclosing {
+	found := matchEnclosing(shortcuts, filepath.Clean(path))
+	if len(found) == 0 {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != filepath.Clean(path) {
+			found = matchEnclosing(shortcuts, resolved)
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return len(filepath.Clean(found[i].Path)) > len(filepath.Clean(found[j].Path))
+	})
+	return found
+}
+
+func matchEnclosing(shortcuts []storage.Shortcut, path string) []enclosing {
+	var found []enclosing
+	for _, sc := range shortcuts {
+		rel, ok := subpath(sc.Path, path)
+		if ok {
+			found = append(found, enclosing{Shortcut: sc, Rel: rel})
+		}
+	}
+	return found
+}
+
+// Where path sits under root, or false when it is outside root
+func subpath(root, path string) (string, bool) {
+	rel, err := filepath.Rel(filepath.Clean(root), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// The innermost shortcuts, several when they share a folder
+func innermost(found []enclosing) []enclosing {
+	for i := range found {
+		if filepath.Clean(found[i].Path) != filepath.Clean(found[0].Path) {
+			return found[:i]
+		}
+	}
+	return found
+}
+
+func (a *app) newWhichCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "which [path]",
+		Short: "Show the shortcut a directory belongs to",
+		Long: `Show the shortcut whose folder holds path (default: the current directory)
+and where path sits under it, e.g. "api:internal/ui". Nested shortcuts
+resolve to the innermost one; --all lists every enclosing shortcut.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			all, _ := cmd.Flags().GetBool("all")
+
+			path := "."
+			if len(args) == 1 {
+				path = args[0]
+			}
+			path, err := a.expandPath(path)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+
+			shortcuts, err := a.store.ListShortcuts()
+			if err != nil {
+				return err
+			}
+			found := enclosingShortcuts(shortcuts, path)
+			if !all {
+				found = innermost(found)
+			}
+
+			if asJSON {
+				if err := writeEnclosingJSON(a.stdout, found); err != nil {
+					return err
+				}
+				if len(found) == 0 {
+					return &exitError{code: 1}
+				}
+				return nil
+			}
+
+			if len(found) == 0 {
+				return fmt.Errorf("no shortcut contains %s", path)
+			}
+			for _, e := range found {
+				if e.Rel == "" {
+					fmt.Fprintln(a.stdout, e.Name)
+				} else {
+					fmt.Fprintf(a.stdout, "%s:%s\n", e.Name, e.Rel)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Print the matches as JSON")
+	cmd.Flags().BoolP("all", "a", false, "List every enclosing shortcut, innermost first")
+	return cmd
+}
+
+func writeEnclosingJSON(w io.Writer, found []enclosing) error {
+	type enclosingJSON struct {
+		Name    string   `json:"name"`
+		Path    string   `json:"path"`
+		Tags    []string `json:"tags"`
+		Subpath string   `json:"subpath"`
+	}
+
+	out := make([]enclosingJSON, len(found))
+	for i, e := range found {
+		tags := e.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		out[i] = enclosingJSON{Name: e.Name, Path: e.Path, Tags: tags, Subpath: e.Rel}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// The folder `fs go ..name` means: the root of the innermost shortcut
+// holding the current directory whose name starts with prefix. A bare ".."
+// skips a shortcut whose root is the current directory itself, so repeating
+// it climbs out through nested shortcuts.
+func (a *app) enclosingRoot(prefix string) (*storage.Shortcut, error) {
+	cwd, err := a.getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	shortcuts, err := a.store.ListShortcuts()
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := ""
+	for _, e := range enclosingShortcuts(shortcuts, cwd) {
+		if prefix == "" && e.Rel == "" {
+			skipped = e.Name
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(e.Name), strings.ToLower(prefix)) {
+			sc := e.Shortcut
+			return &sc, nil
+		}
+	}
+
+	switch {
+	case prefix != "":
+		return nil, fmt.Errorf("no shortcut named %s... contains %s", prefix, cwd)
+	case skipped != "":
+		return nil, fmt.Errorf("no shortcut contains %s", skipped)
+	}
+	return nil, fmt.Errorf("no shortcut contains %s", cwd)
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	backupPrefix   = "shortcuts-"
+	backupExt      = ".db"
+	backupIDFormat = "20060102-150405"
+)
+
+// Where backups of dbPath are kept
+func DefaultBackupDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), "backups")
+}
+
+// Write a consistent copy of the database into dir, then drop the oldest
+// backups so at most keep remain. A keep of 0 skips rotation; the backup
+// is rotated out by the next PruneBackups or CreateBackup with a keep.
+func (s *SQLiteStorage) CreateBackup(dir string, keep int) (*Backup, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	now := time.Now()
+	id := now.Format(backupIDFormat)
+	path := backupPath(dir, id)
+	for n := 2; fileExists(path); n++ {
+		id = fmt.Sprintf("%s-%d", now.Format(backupIDFormat), n)
+		path = backupPath(dir, id)
+	}
+
+	// VACUUM INTO takes a transactionally consistent snapshot of a live database
+	if _, err := s.db.Exec("VACUUM INTO ?", path); err != nil {
+		return nil, fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat backup: %w", err)
+	}
+
+	if keep > 0 {
+		if err := PruneBackups(dir, keep); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Backup{ID: id, Path: path, CreatedAt: now, Size: info.Size()}, nil
+}
+
+// List backups in dir, newest first
+func ListBackups(dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupExt) {
+			continue
+		}
+
+		id := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupExt)
+		createdAt, _, ok := parseBackupID(id)
+		if !ok {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat backup: %w", err)
+		}
+
+		backups = append(backups, Backup{
+			ID:        id,
+			Path:      filepath.Join(dir, name),
+			CreatedAt: createdAt,
+			Size:      info.Size(),
+		})
+	}
+
+	// Backups from the same second are numbered, and -10 comes after -9
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].CreatedAt.Equal(backups[j].CreatedAt) {
+			return backups[i].CreatedAt.After(backups[j].CreatedAt)
+		}
+		_, seqI, _ := parseBackupID(backups[i].ID)
+		_, seqJ, _ := parseBackupID(backups[j].ID)
+		return seqI > seqJ
+	})
+
+	return backups, nil
+}
+
+// Split a backup ID into its timestamp and its number within that second,
+// which is 1 for the first backup and n for an ID ending in -n
+func parseBackupID(id string) (time.Time, int, bool) {
+	stamp, suffix, numbered := id, "", false
+	if len(id) > len(backupIDFormat) {
+		stamp, suffix = id[:len(backupIDFormat)], id[len(backupIDFormat):]
+		suffix, numbered = strings.CutPrefix(suffix, "-")
+		if !numbered {
+			return time.Time{}, 0, false
+		}
+	}
+
+	createdAt, err := time.ParseInLocation(backupIDFormat, stamp, time.Local)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	if !numbered {
+		return createdAt, 1, true
+	}
+
+	seq, err := strconv.Atoi(suffix)
+	if err != nil || seq < 2 {
+		return time.Time{}, 0, false
+	}
+	return createdAt, seq, true
+}
+
+// Find a backup by ID
+func FindBackup(dir, id string) (*Backup, error) {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range backups {
+		if b.ID == id {
+			return &b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("backup '%s' not found", id)
+}
+
+// Run PRAGMA integrity_check against a backup file
+func VerifyBackup(path string) error {
+	if !fileExists(path) {
+		return fmt.Errorf("backup file not found: %s", path)
+	}
+
+	// Build the URI so a ? or # in the path isn't read as part of the query
+	uri := url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro"}
+	db, err := sql.Open("sqlite", uri.String())
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("failed to check backup integrity: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("backup failed integrity check: %s", result)
+	}
+
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'shortcuts'").Scan(&tables); err != nil {
+		return fmt.Errorf("failed to inspect backup: %w", err)
+	}
+	if tables == 0 {
+		return fmt.Errorf("backup does not contain a shortcuts table")
+	}
+
+	return nil
+}
+
+// Replace the database at dbPath with a verified backup.
+// The storage using dbPath must be closed first.
+func RestoreBackup(backupPath, dbPath string) error {
+	if err := VerifyBackup(backupPath); err != nil {
+		return err
+	}
+
+	src, err := os.Open(backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer src.Close()
+
+	// Copy next to the database and rename so a failed copy never leaves a partial file
+	tmpPath := dbPath + ".restore"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create restore file: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy backup: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write restore file: %w", err)
+	}
+
+	// Stale journal files would be replayed against the restored database
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("failed to remove %s file: %w", suffix, err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace database: %w", err)
+	}
+
+	// The restored file could match the old cache header by chance
+	if err := os.Remove(CachePath(dbPath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove name cache: %w", err)
+	}
+
+	return nil
+}
+
+// Remove all but the newest keep backups in dir
+func PruneBackups(dir string, keep int) error {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return err
+	}
+
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func backupPath(dir, id string) string {
+	return filepath.Join(dir, backupPrefix+id+backupExt)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
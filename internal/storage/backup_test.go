@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCreateBackup_RotatesOldBackups(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+	dir := filepath.Join(t.TempDir(), "backups")
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := s.CreateBackup(dir, 3); err != nil {
+			t.Fatalf("CreateBackup returned error: %v", err)
+		}
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatalf("ListBackups returned error: %v", err)
+	}
+	if len(backups) != 3 {
+		t.Fatalf("expected 3 backups after rotation, got %d", len(backups))
+	}
+
+	for _, b := range backups {
+		if err := VerifyBackup(b.Path); err != nil {
+			t.Fatalf("expected backup %s to pass integrity check: %v", b.ID, err)
+		}
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	dir := DefaultBackupDir(dbPath)
+
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	b, err := s.CreateBackup(dir, 0)
+	if err != nil {
+		t.Fatalf("CreateBackup returned error: %v", err)
+	}
+	if err := s.AddShortcut("api", "/tmp/api"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close storage: %v", err)
+	}
+
+	found, err := FindBackup(dir, b.ID)
+	if err != nil {
+		t.Fatalf("FindBackup returned error: %v", err)
+	}
+	if err := RestoreBackup(found.Path, dbPath); err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+
+	s, err = NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	shortcuts, err := s.ListShortcuts()
+	if err != nil {
+		t.Fatalf("ListShortcuts returned error: %v", err)
+	}
+	if len(shortcuts) != 1 || shortcuts[0].Name != "cli" {
+		t.Fatalf("expected only cli after restore, got %v", shortcutNames(shortcuts))
+	}
+}
+
+func TestRestoreBackup_RejectsCorruptBackup(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "shortcuts.db")
+	corrupt := filepath.Join(dir, "shortcuts-20250101-000000.db")
+
+	if err := os.WriteFile(dbPath, []byte("current"), 0644); err != nil {
+		t.Fatalf("failed to write db: %v", err)
+	}
+	if err := os.WriteFile(corrupt, []byte("not a database"), 0644); err != nil {
+		t.Fatalf("failed to write corrupt backup: %v", err)
+	}
+
+	if err := RestoreBackup(corrupt, dbPath); err == nil {
+		t.Fatal("expected error restoring a corrupt backup")
+	}
+
+	data, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("failed to read db: %v", err)
+	}
+	if string(data) != "current" {
+		t.Fatal("expected database to be untouched after failed restore")
+	}
+}
+
+func TestNewSQLiteStorage_BacksUpBeforeMigrating(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if _, err := s.db.Exec("PRAGMA user_version = 0"); err != nil {
+		t.Fatalf("failed to reset schema version: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close storage: %v", err)
+	}
+
+	s, err = NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	backups, err := ListBackups(DefaultBackupDir(dbPath))
+	if err != nil {
+		t.Fatalf("ListBackups returned error: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 backup taken before migrating, got %d", len(backups))
+	}
+}
+
+func TestListBackups_OrdersNumberedBackupsByNumber(t *testing.T) {
+	dir := t.TempDir()
+	for _, id := range []string{"20250101-000000", "20250101-000000-2", "20250101-000000-9", "20250101-000000-10", "20250101-000000-x", "20241231-235959"} {
+		if err := os.WriteFile(backupPath(dir, id), nil, 0644); err != nil {
+			t.Fatalf("failed to write backup: %v", err)
+		}
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatalf("ListBackups returned error: %v", err)
+	}
+
+	var ids []string
+	for _, b := range backups {
+		ids = append(ids, b.ID)
+	}
+	want := []string{"20250101-000000-10", "20250101-000000-9", "20250101-000000-2", "20250101-000000", "20241231-235959"}
+	if !slices.Equal(ids, want) {
+		t.Fatalf("unexpected backup order. got=%v want=%v", ids, want)
+	}
+}
+
+func TestPruneBackups_RotatesUnrotatedBackups(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+	dir := filepath.Join(t.TempDir(), "backups")
+
+	var created []string
+	for i := 0; i < 4; i++ {
+		b, err := s.CreateBackup(dir, 0)
+		if err != nil {
+			t.Fatalf("CreateBackup returned error: %v", err)
+		}
+		created = append(created, b.ID)
+	}
+	if err := PruneBackups(dir, 2); err != nil {
+		t.Fatalf("PruneBackups returned error: %v", err)
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatalf("ListBackups returned error: %v", err)
+	}
+	if len(backups) != 2 || backups[0].ID != created[3] || backups[1].ID != created[2] {
+		t.Fatalf("expected the 2 newest of %v to remain, got %+v", created, backups)
+	}
+}
+
+func TestVerifyBackup_PathWithURICharacters(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+	dir := filepath.Join(t.TempDir(), "odd?name#1 %20")
+
+	b, err := s.CreateBackup(dir, 0)
+	if err != nil {
+		t.Fatalf("CreateBackup returned error: %v", err)
+	}
+	if err := VerifyBackup(b.Path); err != nil {
+		t.Fatalf("expected backup under %q to verify: %v", dir, err)
+	}
+}
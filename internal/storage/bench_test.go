@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+var benchSizes = []int{10_000, 100_000}
+
+var benchTags = []string{"go", "rust", "web", "api", "work", "personal", "infra", "docs"}
+
+// Seed n shortcuts in one transaction. Every shortcut gets two tags
+// so tag filters match about a quarter of the rows.
+func newBenchStorage(b testing.TB, n int) *SQLiteStorage {
+	b.Helper()
+
+	s, err := NewSQLiteStorage(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatalf("failed to create storage: %v", err)
+	}
+	b.Cleanup(func() { _ = s.Close() })
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, tag := range benchTags {
+		if _, err := tx.Exec("INSERT INTO tags (name) VALUES (?)", tag); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	insertShortcut, err := tx.Prepare("INSERT INTO shortcuts (name, path) VALUES (?, ?)")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer insertShortcut.Close()
+
+	insertLink, err := tx.Prepare("INSERT OR IGNORE INTO shortcut_tags (shortcut_id, tag_id) VALUES (?, ?)")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer insertLink.Close()
+
+	for i := 1; i <= n; i++ {
+		name := fmt.Sprintf("proj-%06d", i)
+		path := fmt.Sprintf("/home/dev/src/group-%03d/%s", i%500, name)
+		if _, err := insertShortcut.Exec(name, path); err != nil {
+			b.Fatal(err)
+		}
+		for _, tagID := range []int{i%len(benchTags) + 1, (i/len(benchTags))%len(benchTags) + 1} {
+			if _, err := insertLink.Exec(i, tagID); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+	return s
+}
+
+func BenchmarkGetShortcut(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			s := newBenchStorage(b, n)
+			name := fmt.Sprintf("proj-%06d", n/2)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.GetShortcut(name); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSearchShortcuts(b *testing.B) {
+	cases := []struct {
+		name  string
+		query string
+		tags  []string
+		tagOp string
+	}{
+		{name: "all"},
+		{name: "text", query: "group-042"},
+		{name: "tags-or", tags: []string{"go", "rust"}, tagOp: "or"},
+		{name: "tags-and", tags: []string{"go", "web"}, tagOp: "and"},
+		{name: "text+tags", query: "group-04", tags: []string{"go"}, tagOp: "or"},
+	}
+
+	for _, n := range benchSizes {
+		s := newBenchStorage(b, n)
+		for _, c := range cases {
+			b.Run(fmt.Sprintf("%s/n=%d", c.name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := s.SearchShortcuts(c.query, c.tags, c.tagOp); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkAttachTagsToShortcuts(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			s := newBenchStorage(b, n)
+			shortcuts, err := s.ListShortcuts()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := s.attachTagsToShortcuts(shortcuts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Latency budget for the commands on the interactive path. `fs go` is a
+// single GetShortcut; `fs find` is one SearchShortcuts before the selector.
+// Budgets leave headroom for slow CI machines; see README "Performance".
+var latencyBudgets = []struct {
+	name   string
+	n      int
+	budget time.Duration
+	run    func(s *SQLiteStorage) error
+}{
+	{
+		name:   "GetShortcut",
+		n:      100_000,
+		budget: time.Millisecond,
+		run: func(s *SQLiteStorage) error {
+			_, err := s.GetShortcut("proj-050000")
+			return err
+		},
+	},
+	{
+		name:   "SearchShortcuts/text",
+		n:      10_000,
+		budget: 50 * time.Millisecond,
+		run: func(s *SQLiteStorage) error {
+			_, err := s.SearchShortcuts("group-042", nil, "or")
+			return err
+		},
+	},
+	{
+		name:   "SearchShortcuts/tags-and",
+		n:      10_000,
+		budget: 75 * time.Millisecond,
+		run: func(s *SQLiteStorage) error {
+			_, err := s.SearchShortcuts("", []string{"go", "web"}, "and")
+			return err
+		},
+	},
+	{
+		name:   "SearchShortcuts/all",
+		n:      10_000,
+		budget: 500 * time.Millisecond,
+		run: func(s *SQLiteStorage) error {
+			_, err := s.SearchShortcuts("", nil, "or")
+			return err
+		},
+	},
+}
+
+// Default slowdown against the baseline that fails the check, in percent
+const defaultBenchThreshold = 20
+
+// Each operation must stay within its budget, and within FS_BENCH_THRESHOLD
+// percent of the baseline in FS_BENCH_BASELINE (`make bench-baseline`) when
+// one is recorded.
+func TestLatencyBudget(t *testing.T) {
+	if os.Getenv("FS_BENCH_BUDGET") == "" {
+		t.Skip("set FS_BENCH_BUDGET=1 (make bench-check) to enforce the latency budget")
+	}
+
+	threshold := defaultBenchThreshold
+	if v := os.Getenv("FS_BENCH_THRESHOLD"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			t.Fatalf("invalid FS_BENCH_THRESHOLD %q: expected a percentage", v)
+		}
+		threshold = n
+	}
+
+	var baseline map[string]time.Duration
+	if path := os.Getenv("FS_BENCH_BASELINE"); path != "" {
+		var err error
+		if baseline, err = readBenchBaseline(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stores := make(map[int]*SQLiteStorage)
+	for _, lb := range latencyBudgets {
+		s, ok := stores[lb.n]
+		if !ok {
+			s = newBenchStorage(t, lb.n)
+			stores[lb.n] = s
+		}
+
+		name := fmt.Sprintf("%s/n=%d", lb.name, lb.n)
+		t.Run(name, func(t *testing.T) {
+			var runErr error
+			result := testing.Benchmark(func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := lb.run(s); err != nil {
+						runErr = err
+						return
+					}
+				}
+			})
+			if runErr != nil {
+				t.Fatal(runErr)
+			}
+
+			perOp := time.Duration(result.NsPerOp())
+			t.Logf("%v per op (budget %v)", perOp, lb.budget)
+			if perOp > lb.budget {
+				t.Errorf("%v per op exceeds budget of %v", perOp, lb.budget)
+			}
+
+			base, ok := baseline["Benchmark"+name]
+			if !ok {
+				if baseline != nil {
+					t.Logf("no baseline for Benchmark%s", name)
+				}
+				return
+			}
+			limit := base + base*time.Duration(threshold)/100
+			t.Logf("baseline %v, limit %v (+%d%%)", base, limit, threshold)
+			if perOp > limit {
+				t.Errorf("%v per op is more than %d%% slower than the baseline of %v", perOp, threshold, base)
+			}
+		})
+	}
+}
+
+// A benchmark result line: the name without its -GOMAXPROCS suffix, and ns/op
+var benchLine = regexp.MustCompile(`^(Benchmark\S+?)(?:-\d+)?\s+\d+\s+([0-9.]+) ns/op`)
+
+// The median ns/op of each benchmark in go test -bench output
+func readBenchBaseline(path string) (map[string]time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open benchmark baseline: %w", err)
+	}
+	defer f.Close()
+
+	runs := make(map[string][]float64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := benchLine.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		ns, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse benchmark baseline: %w", err)
+		}
+		runs[m[1]] = append(runs[m[1]], ns)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read benchmark baseline: %w", err)
+	}
+
+	medians := make(map[string]time.Duration, len(runs))
+	for name, ns := range runs {
+		slices.Sort(ns)
+		medians[name] = time.Duration(ns[len(ns)/2])
+	}
+	return medians, nil
+}
+
+func TestReadBenchBaseline_TakesTheMedian(t *testing.T) {
+	path := t.TempDir() + "/bench.txt"
+	data := `goos: linux
+BenchmarkGetShortcut/n=100000-8   	   50000	     30000 ns/op	    1200 B/op
+BenchmarkGetShortcut/n=100000-8   	   50000	     10000 ns/op	    1200 B/op
+BenchmarkGetShortcut/n=100000-8   	   50000	     20000 ns/op	    1200 B/op
+BenchmarkSearchShortcuts/text/n=10000   	 148	   7189191 ns/op
+PASS
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readBenchBaseline(path)
+	if err != nil {
+		t.Fatalf("readBenchBaseline returned error: %v", err)
+	}
+	if got["BenchmarkGetShortcut/n=100000"] != 20*time.Microsecond {
+		t.Fatalf("expected the median of the runs, got %v", got)
+	}
+	if got["BenchmarkSearchShortcuts/text/n=10000"] != 7189191 {
+		t.Fatalf("expected a run without a GOMAXPROCS suffix, got %v", got)
+	}
+}
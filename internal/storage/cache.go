@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// The name cache is a plain text file next to the database that lets
+// `fs go` and `fs prompt` resolve shortcuts without opening SQLite:
+//
+//	fs-cache 2 <change counter> <db size> <db mtime ns>
+//	name<TAB>path[<TAB>tag]...
+//	...
+//
+// The header records the database's state when the cache was written. Any
+// later write to the database changes its file change counter (offset 24
+// of the SQLite header), so a mismatch means the cache is stale and callers
+// must fall back to SQLite. Close rewrites the cache whenever it is stale.
+const cacheMagic = "fs-cache 2"
+
+// Where the name cache for dbPath lives
+func CachePath(dbPath string) string {
+	return dbPath + ".cache"
+}
+
+// Path for name from the cache, or false when the cache is missing, stale
+// or has no entry for name. Also false once the visit log is full, so the
+// caller's fallback to SQLite folds it.
+func LookupCache(dbPath, name string) (string, bool) {
+	if visitLogFull(dbPath) {
+		return "", false
+	}
+
+	data, ok := readCache(dbPath)
+	if !ok {
+		return "", false
+	}
+
+	entry := []byte("\n" + name + "\t")
+	start := bytes.Index(data, entry)
+	if start == -1 {
+		return "", false
+	}
+	rest := data[start+len(entry):]
+	end := bytes.IndexAny(rest, "\t\n")
+	if end == -1 {
+		return "", false
+	}
+
+	return string(rest[:end]), true
+}
+
+// Every cached shortcut with its name, path and tags, or false when the
+// cache is missing or stale
+func ReadCache(dbPath string) ([]Shortcut, bool) {
+	data, ok := readCache(dbPath)
+	if !ok {
+		return nil, false
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	shortcuts := make([]Shortcut, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			return nil, false
+		}
+		shortcuts = append(shortcuts, Shortcut{Name: fields[0], Path: fields[1], Tags: fields[2:]})
+	}
+	return shortcuts, true
+}
+
+// Contents of the cache when it matches the database
+func readCache(dbPath string) ([]byte, bool) {
+	state, ok := dbState(dbPath)
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(CachePath(dbPath))
+	if err != nil || !bytes.HasPrefix(data, []byte(state+"\n")) {
+		return nil, false
+	}
+	return data, true
+}
+
+// Header line describing the database file as it is now. WAL mode leaves
+// the main file untouched until a checkpoint, so it never validates.
+func dbState(dbPath string) (string, bool) {
+	if fileExists(dbPath + "-wal") {
+		return "", false
+	}
+
+	f, err := os.Open(dbPath)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", false
+	}
+
+	header := make([]byte, 28)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return "", false
+	}
+	counter := binary.BigEndian.Uint32(header[24:28])
+
+	return fmt.Sprintf("%s %d %d %d", cacheMagic, counter, info.Size(), info.ModTime().UnixNano()), true
+}
+
+// Rewrite the cache if the database changed since it was written
+func (s *SQLiteStorage) refreshCache() error {
+	state, ok := dbState(s.path)
+	if !ok {
+		return nil
+	}
+
+	cachePath := CachePath(s.path)
+	if f, err := os.Open(cachePath); err == nil {
+		header, _ := bufio.NewReader(f).ReadString('\n')
+		_ = f.Close()
+		if strings.TrimSuffix(header, "\n") == state {
+			return nil
+		}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT s.name, s.path, COALESCE(t.name, '')
+		FROM shortcuts s
+		LEFT JOIN shortcut_tags st ON st.shortcut_id = s.id
+		LEFT JOIN tags t ON t.id = st.tag_id
+		ORDER BY s.name, t.name
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to read shortcuts for cache: %w", err)
+	}
+	defer rows.Close()
+
+	// One row per tag, folded into one line per shortcut
+	var entries [][]string
+	for rows.Next() {
+		var name, path, tag string
+		if err := rows.Scan(&name, &path, &tag); err != nil {
+			return fmt.Errorf("failed to scan shortcut for cache: %w", err)
+		}
+		if len(entries) == 0 || entries[len(entries)-1][0] != name {
+			entries = append(entries, []string{name, path})
+		}
+		if tag != "" {
+			entries[len(entries)-1] = append(entries[len(entries)-1], tag)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate shortcuts for cache: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(state + "\n")
+	partial := false
+	for _, fields := range entries {
+		// Entries that cannot be encoded are left to SQLite
+		if anyContains(fields, "\t\n") {
+			partial = true
+			continue
+		}
+		b.WriteString(strings.Join(fields, "\t") + "\n")
+	}
+	// A line without a tab can't be an entry: it tells ReadCache that the
+	// cache doesn't hold every shortcut
+	if partial {
+		b.WriteString("partial\n")
+	}
+
+	tmp := cachePath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(tmp, cachePath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	return nil
+}
+
+func anyContains(fields []string, chars string) bool {
+	for _, f := range fields {
+		if strings.ContainsAny(f, chars) {
+			return true
+		}
+	}
+	return false
+}
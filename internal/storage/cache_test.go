@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLookupCache_WrittenOnClose(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close storage: %v", err)
+	}
+
+	path, ok := LookupCache(dbPath, "cli")
+	if !ok || path != "/tmp/cli" {
+		t.Fatalf("expected cached /tmp/cli, got %q (ok=%v)", path, ok)
+	}
+	if _, ok := LookupCache(dbPath, "cl"); ok {
+		t.Fatal("expected prefix of a name to miss")
+	}
+}
+
+func TestLookupCache_StaleAfterWrite(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.refreshCache(); err != nil {
+		t.Fatalf("failed to write cache: %v", err)
+	}
+	if _, ok := LookupCache(dbPath, "cli"); !ok {
+		t.Fatal("expected fresh cache to hit")
+	}
+
+	// A write the cache hasn't seen yet, e.g. another process mid-command
+	if err := s.UpdateShortcutPath("cli", "/tmp/moved"); err != nil {
+		t.Fatalf("failed to update path: %v", err)
+	}
+	if path, ok := LookupCache(dbPath, "cli"); ok {
+		t.Fatalf("expected stale cache to miss, got %q", path)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close storage: %v", err)
+	}
+	if path, ok := LookupCache(dbPath, "cli"); !ok || path != "/tmp/moved" {
+		t.Fatalf("expected refreshed cache with /tmp/moved, got %q (ok=%v)", path, ok)
+	}
+}
+
+func TestReadCache_ListsShortcutsWithTags(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	for _, name := range []string{"web", "api"} {
+		if err := s.AddShortcut(name, "/tmp/"+name); err != nil {
+			t.Fatalf("failed to add shortcut: %v", err)
+		}
+	}
+	if err := s.AddTags("api", []string{"work", "go"}); err != nil {
+		t.Fatalf("failed to add tags: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close storage: %v", err)
+	}
+
+	shortcuts, ok := ReadCache(dbPath)
+	if !ok {
+		t.Fatal("expected a fresh cache")
+	}
+	want := []Shortcut{
+		{Name: "api", Path: "/tmp/api", Tags: []string{"go", "work"}},
+		{Name: "web", Path: "/tmp/web", Tags: []string{}},
+	}
+	if !reflect.DeepEqual(shortcuts, want) {
+		t.Fatalf("ReadCache = %+v, want %+v", shortcuts, want)
+	}
+	if path, ok := LookupCache(dbPath, "api"); !ok || path != "/tmp/api" {
+		t.Fatalf("expected the path without its tags, got %q (ok=%v)", path, ok)
+	}
+}
+
+func TestReadCache_MissesWhenAShortcutIsLeftOut(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if err := s.AddShortcut("api", "/tmp/api"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.AddShortcut("odd", "/tmp/tab\there"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close storage: %v", err)
+	}
+
+	if _, ok := ReadCache(dbPath); ok {
+		t.Fatal("expected a cache missing a shortcut not to list them")
+	}
+	if path, ok := LookupCache(dbPath, "api"); !ok || path != "/tmp/api" {
+		t.Fatalf("expected lookups to still hit, got %q (ok=%v)", path, ok)
+	}
+}
+
+func TestLookupCache_MissingDatabase(t *testing.T) {
+	if _, ok := LookupCache(filepath.Join(t.TempDir(), "missing.db"), "cli"); ok {
+		t.Fatal("expected lookup without a database to miss")
+	}
+}
+
+func TestMigrate_SkipsDDLWhenVersionCurrent(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if _, err := s.db.Exec("DROP INDEX idx_shortcut_tags_tag_id"); err != nil {
+		t.Fatalf("failed to drop index: %v", err)
+	}
+	_ = s.Close()
+
+	s, err = NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	var indexes int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'idx_shortcut_tags_tag_id'").Scan(&indexes); err != nil {
+		t.Fatalf("failed to inspect schema: %v", err)
+	}
+	if indexes != 0 {
+		t.Fatal("expected reopening a current database to skip schema DDL")
+	}
+}
+
+func TestRestoreBackup_RemovesCache(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	backup, err := s.CreateBackup(filepath.Join(dir, "backups"), 0)
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	_ = s.Close()
+
+	if err := RestoreBackup(backup.Path, dbPath); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if _, err := os.Stat(CachePath(dbPath)); !os.IsNotExist(err) {
+		t.Fatalf("expected cache to be removed after restore, got %v", err)
+	}
+}
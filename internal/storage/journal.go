@@ -0,0 +1,360 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Number of operations kept in the journal
+const maxJournalEntries = 500
+
+// snapshot is the serialized state of a shortcut stored in the journal
+type snapshot struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Usage survives delete and undo, but isn't part of what an operation changes
+	Visits        int    `json:"visits,omitempty"`
+	LastVisitedAt string `json:"last_visited_at,omitempty"`
+}
+
+// Run fn in a transaction and journal the shortcut state before and after it.
+// fn returns the name of the shortcut once it's done, or "" if it no longer exists.
+func (s *SQLiteStorage) journal(kind, name string, fn func(tx *sql.Tx) (string, error)) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := loadSnapshot(tx, name)
+	if err != nil {
+		return err
+	}
+
+	afterName, err := fn(tx)
+	if err != nil {
+		return err
+	}
+
+	var after *snapshot
+	if afterName != "" {
+		if after, err = loadSnapshot(tx, afterName); err != nil {
+			return err
+		}
+	}
+
+	// Nothing changed (e.g. removing a tag that wasn't there), nothing to undo
+	if !sameSnapshot(before, after) {
+		if err := recordOperation(tx, kind, name, before, after); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func loadSnapshot(q queryer, name string) (*snapshot, error) {
+	var id int
+	var snap snapshot
+	var lastVisited sql.NullString
+	err := q.QueryRow(
+		"SELECT id, name, path, created_at, visits, last_visited_at FROM shortcuts WHERE name = ?",
+		name,
+	).Scan(&id, &snap.Name, &snap.Path, &snap.CreatedAt, &snap.Visits, &lastVisited)
+	snap.LastVisitedAt = lastVisited.String
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shortcut state: %w", err)
+	}
+
+	rows, err := q.Query(`
+		SELECT t.name
+		FROM tags t
+		JOIN shortcut_tags st ON t.id = st.tag_id
+		WHERE st.shortcut_id = ?
+		ORDER BY t.name
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shortcut tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		snap.Tags = append(snap.Tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tags: %w", err)
+	}
+
+	return &snap, nil
+}
+
+func sameSnapshot(a, b *snapshot) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Name == b.Name && a.Path == b.Path && slices.Equal(a.Tags, b.Tags)
+}
+
+func recordOperation(tx *sql.Tx, kind, name string, before, after *snapshot) error {
+	beforeJSON, err := encodeSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := encodeSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	// A new operation invalidates anything that could have been redone
+	if _, err := tx.Exec("DELETE FROM operations WHERE undone = 1"); err != nil {
+		return fmt.Errorf("failed to clear redo history: %w", err)
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO operations (kind, shortcut, before_state, after_state) VALUES (?, ?, ?, ?)",
+		kind, name, beforeJSON, afterJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record operation: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to read operation ID: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM operations WHERE id <= ?", id-maxJournalEntries); err != nil {
+		return fmt.Errorf("failed to prune operation history: %w", err)
+	}
+
+	return nil
+}
+
+func encodeSnapshot(snap *snapshot) (sql.NullString, error) {
+	if snap == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encode shortcut state: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func decodeSnapshot(data sql.NullString) (*snapshot, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+	var snap snapshot
+	if err := json.Unmarshal([]byte(data.String), &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode shortcut state: %w", err)
+	}
+	return &snap, nil
+}
+
+// Replace the shortcut state from with the state to. Either may be nil
+// to create or delete the shortcut; deletions go through the trash.
+func applySnapshot(tx *sql.Tx, from, to *snapshot) error {
+	if from == nil {
+		if to == nil {
+			return nil
+		}
+		var exists int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM shortcuts WHERE name = ?", to.Name).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check for existing shortcut: %w", err)
+		}
+		if exists > 0 {
+			return fmt.Errorf("shortcut '%s' already exists", to.Name)
+		}
+
+		var lastVisited sql.NullString
+		if to.LastVisitedAt != "" {
+			lastVisited = sql.NullString{String: to.LastVisitedAt, Valid: true}
+		}
+		result, err := tx.Exec(
+			"INSERT INTO shortcuts (name, path, created_at, visits, last_visited_at) VALUES (?, ?, ?, ?, ?)",
+			to.Name, to.Path, to.CreatedAt, to.Visits, lastVisited,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to restore shortcut: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to read shortcut ID: %w", err)
+		}
+		if err := untrashShortcut(tx, to.Name); err != nil {
+			return err
+		}
+		return linkTags(tx, int(id), to.Tags)
+	}
+
+	shortcutID, err := findShortcutID(tx, from.Name)
+	if err != nil {
+		return fmt.Errorf("cannot revert, %w", err)
+	}
+
+	if to == nil {
+		return trashShortcut(tx, from.Name)
+	}
+
+	if to.Name != from.Name {
+		var exists int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM shortcuts WHERE name = ?", to.Name).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check for existing shortcut: %w", err)
+		}
+		if exists > 0 {
+			return fmt.Errorf("shortcut '%s' already exists", to.Name)
+		}
+	}
+
+	_, err = tx.Exec(
+		"UPDATE shortcuts SET name = ?, path = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		to.Name, to.Path, shortcutID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update shortcut: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM shortcut_tags WHERE shortcut_id = ?", shortcutID); err != nil {
+		return fmt.Errorf("failed to reset tags: %w", err)
+	}
+	return linkTags(tx, shortcutID, to.Tags)
+}
+
+// Revert the most recent operation that hasn't been undone
+func (s *SQLiteStorage) Undo() (*Operation, error) {
+	return s.step(
+		"SELECT id, kind, shortcut, before_state, after_state, undone, created_at FROM operations WHERE undone = 0 ORDER BY id DESC LIMIT 1",
+		"nothing to undo",
+		true,
+	)
+}
+
+// Reapply the most recently undone operation
+func (s *SQLiteStorage) Redo() (*Operation, error) {
+	return s.step(
+		"SELECT id, kind, shortcut, before_state, after_state, undone, created_at FROM operations WHERE undone = 1 ORDER BY id ASC LIMIT 1",
+		"nothing to redo",
+		false,
+	)
+}
+
+func (s *SQLiteStorage) step(query, emptyMsg string, undo bool) (*Operation, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	op, before, after, err := scanOperation(tx.QueryRow(query))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s", emptyMsg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if undo {
+		err = applySnapshot(tx, after, before)
+	} else {
+		err = applySnapshot(tx, before, after)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE operations SET undone = ? WHERE id = ?", undo, op.ID); err != nil {
+		return nil, fmt.Errorf("failed to update operation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	op.Undone = undo
+	return op, nil
+}
+
+// List the most recent operations, newest first
+func (s *SQLiteStorage) History(limit int) ([]Operation, error) {
+	rows, err := s.db.Query(
+		"SELECT id, kind, shortcut, before_state, after_state, undone, created_at FROM operations ORDER BY id DESC LIMIT ?",
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []Operation
+	for rows.Next() {
+		op, _, _, err := scanOperation(rows)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, *op)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate history: %w", err)
+	}
+
+	return ops, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOperation(row rowScanner) (*Operation, *snapshot, *snapshot, error) {
+	var op Operation
+	var beforeJSON, afterJSON sql.NullString
+	err := row.Scan(&op.ID, &op.Kind, &op.Shortcut, &beforeJSON, &afterJSON, &op.Undone, &op.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to scan operation: %w", err)
+	}
+
+	before, err := decodeSnapshot(beforeJSON)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	after, err := decodeSnapshot(afterJSON)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	op.Before = before.shortcut()
+	op.After = after.shortcut()
+	return &op, before, after, nil
+}
+
+func (snap *snapshot) shortcut() *Shortcut {
+	if snap == nil {
+		return nil
+	}
+	return &Shortcut{
+		Name:      snap.Name,
+		Path:      snap.Path,
+		Tags:      snap.Tags,
+		CreatedAt: snap.CreatedAt,
+	}
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUndo_RestoresDeletedShortcutWithTags(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.AddTags("cli", []string{"go", "proj"}); err != nil {
+		t.Fatalf("failed to add tags: %v", err)
+	}
+	original, err := s.GetShortcut("cli")
+	if err != nil {
+		t.Fatalf("GetShortcut returned error: %v", err)
+	}
+
+	if err := s.DeleteShortcut("cli"); err != nil {
+		t.Fatalf("DeleteShortcut returned error: %v", err)
+	}
+
+	op, err := s.Undo()
+	if err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+	if op.Kind != OpDelete || !op.Undone {
+		t.Fatalf("expected undone delete operation, got %+v", op)
+	}
+
+	sc, err := s.GetShortcut("cli")
+	if err != nil {
+		t.Fatalf("expected shortcut to be restored: %v", err)
+	}
+	if sc.Path != "/tmp/cli" {
+		t.Fatalf("unexpected restored path %q", sc.Path)
+	}
+	if !sc.CreatedAt.Equal(original.CreatedAt) {
+		t.Fatalf("expected created_at to be preserved. got=%v want=%v", sc.CreatedAt, original.CreatedAt)
+	}
+
+	tags, err := s.GetShortcutTags("cli")
+	if err != nil {
+		t.Fatalf("GetShortcutTags returned error: %v", err)
+	}
+	if !slices.Equal(tags, []string{"go", "proj"}) {
+		t.Fatalf("expected tags to be restored, got %v", tags)
+	}
+}
+
+func TestUndo_RemoveAllTags(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.AddTags("cli", []string{"go", "proj"}); err != nil {
+		t.Fatalf("failed to add tags: %v", err)
+	}
+	if err := s.RemoveAllTags("cli"); err != nil {
+		t.Fatalf("RemoveAllTags returned error: %v", err)
+	}
+
+	if _, err := s.Undo(); err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+
+	tags, err := s.GetShortcutTags("cli")
+	if err != nil {
+		t.Fatalf("GetShortcutTags returned error: %v", err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 tags after undo, got %v", tags)
+	}
+}
+
+func TestUndoRedo_Rename(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("old", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.UpdateShortcutName("old", "new"); err != nil {
+		t.Fatalf("UpdateShortcutName returned error: %v", err)
+	}
+
+	if _, err := s.Undo(); err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+	if _, err := s.GetShortcut("old"); err != nil {
+		t.Fatalf("expected old name after undo: %v", err)
+	}
+
+	op, err := s.Redo()
+	if err != nil {
+		t.Fatalf("Redo returned error: %v", err)
+	}
+	if op.Kind != OpEditName || op.Undone {
+		t.Fatalf("expected redone rename, got %+v", op)
+	}
+	if _, err := s.GetShortcut("new"); err != nil {
+		t.Fatalf("expected new name after redo: %v", err)
+	}
+}
+
+func TestUndo_StepsBackThroughHistory(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/one"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.UpdateShortcutPath("cli", "/tmp/two"); err != nil {
+		t.Fatalf("UpdateShortcutPath returned error: %v", err)
+	}
+
+	if _, err := s.Undo(); err != nil {
+		t.Fatalf("first Undo returned error: %v", err)
+	}
+	sc, err := s.GetShortcut("cli")
+	if err != nil || sc.Path != "/tmp/one" {
+		t.Fatalf("expected path /tmp/one after first undo, got %v (%v)", sc, err)
+	}
+
+	if _, err := s.Undo(); err != nil {
+		t.Fatalf("second Undo returned error: %v", err)
+	}
+	if _, err := s.GetShortcut("cli"); err == nil {
+		t.Fatal("expected shortcut to be gone after undoing add")
+	}
+
+	if _, err := s.Undo(); err == nil {
+		t.Fatal("expected error when nothing is left to undo")
+	}
+}
+
+func TestRedo_ClearedByNewOperation(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if _, err := s.Undo(); err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+	if err := s.AddShortcut("api", "/tmp/api"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+
+	if _, err := s.Redo(); err == nil {
+		t.Fatal("expected redo history to be cleared by a new operation")
+	}
+}
+
+func TestHistory_SkipsNoOpsAndListsNewestFirst(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.RemoveTags("cli", []string{"missing"}); err != nil {
+		t.Fatalf("RemoveTags returned error: %v", err)
+	}
+	if err := s.AddTags("cli", []string{"go"}); err != nil {
+		t.Fatalf("failed to add tags: %v", err)
+	}
+
+	ops, err := s.History(10)
+	if err != nil {
+		t.Fatalf("History returned error: %v", err)
+	}
+
+	if len(ops) != 2 {
+		t.Fatalf("expected 2 operations, got %d (%+v)", len(ops), ops)
+	}
+	if ops[0].Kind != OpTag || ops[1].Kind != OpAdd {
+		t.Fatalf("expected [tag add], got [%s %s]", ops[0].Kind, ops[1].Kind)
+	}
+	if ops[1].Before != nil || ops[1].After == nil || ops[1].After.Path != "/tmp/cli" {
+		t.Fatalf("unexpected add snapshot: %+v", ops[1])
+	}
+}
+
+func TestUndo_ConflictWhenShortcutChanged(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.DeleteShortcut("cli"); err != nil {
+		t.Fatalf("DeleteShortcut returned error: %v", err)
+	}
+	if _, err := s.db.Exec("INSERT INTO shortcuts (name, path) VALUES ('cli', '/tmp/other')"); err != nil {
+		t.Fatalf("failed to insert conflicting shortcut: %v", err)
+	}
+
+	if _, err := s.Undo(); err == nil {
+		t.Fatal("expected undo to fail when the name is taken again")
+	}
+}
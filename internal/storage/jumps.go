@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Jumps work like the operations journal: fs back marks the latest jump of
+// a session undone and returns to where it started, fs forward takes the
+// oldest undone one again, and a new jump drops the undone ones.
+
+// Jumps kept per session, and how long any jump is kept
+const (
+	maxSessionJumps = 200
+	jumpRetention   = 30 * 24 * time.Hour
+)
+
+const jumpColumns = "id, session, shortcut, from_path, path, undone, created_at"
+
+// Add a jump to its session, pruning old ones
+func (s *SQLiteStorage) RecordJump(jump Jump) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := insertJump(tx, jump); err != nil {
+		return err
+	}
+	if err := pruneJumps(tx, jump.Session, jump.At); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func insertJump(tx *sql.Tx, jump Jump) error {
+	if _, err := tx.Exec("DELETE FROM jumps WHERE session = ? AND undone = 1", jump.Session); err != nil {
+		return fmt.Errorf("failed to drop undone jumps: %w", err)
+	}
+
+	_, err := tx.Exec(
+		"INSERT INTO jumps (session, shortcut, from_path, path, created_at) VALUES (?, ?, ?, ?, ?)",
+		jump.Session, jump.Shortcut, jump.From, jump.Path, jump.At.UTC().Format(timestampLayout),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record jump: %w", err)
+	}
+	return nil
+}
+
+// Drop jumps past the retention, and the oldest of session past the limit
+func pruneJumps(tx *sql.Tx, session string, now time.Time) error {
+	cutoff := now.Add(-jumpRetention).UTC().Format(timestampLayout)
+	if _, err := tx.Exec("DELETE FROM jumps WHERE created_at < ?", cutoff); err != nil {
+		return fmt.Errorf("failed to prune jumps: %w", err)
+	}
+
+	_, err := tx.Exec(`
+		DELETE FROM jumps
+		WHERE session = ? AND id NOT IN (
+			SELECT id FROM jumps WHERE session = ? ORDER BY id DESC LIMIT ?
+		)
+	`, session, session, maxSessionJumps)
+	if err != nil {
+		return fmt.Errorf("failed to prune jumps: %w", err)
+	}
+	return nil
+}
+
+// Undo the latest jump of session. The shell goes back to its From.
+func (s *SQLiteStorage) Back(session string) (*Jump, error) {
+	return s.stepJump(
+		"SELECT "+jumpColumns+" FROM jumps WHERE session = ? AND undone = 0 ORDER BY id DESC LIMIT 1",
+		session, "no earlier jump in this session", true,
+	)
+}
+
+// Redo the oldest undone jump of session. The shell goes to its Path.
+func (s *SQLiteStorage) Forward(session string) (*Jump, error) {
+	return s.stepJump(
+		"SELECT "+jumpColumns+" FROM jumps WHERE session = ? AND undone = 1 ORDER BY id ASC LIMIT 1",
+		session, "no later jump in this session", false,
+	)
+}
+
+func (s *SQLiteStorage) stepJump(query, session, emptyMsg string, undo bool) (*Jump, error) {
+	jump, err := scanJump(s.db.QueryRow(query, session))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s", emptyMsg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.Exec("UPDATE jumps SET undone = ? WHERE id = ?", undo, jump.ID); err != nil {
+		return nil, fmt.Errorf("failed to update jump: %w", err)
+	}
+	jump.Undone = undo
+	return jump, nil
+}
+
+// List the jumps of session, newest first, all of them if limit is 0
+func (s *SQLiteStorage) Jumps(session string, limit int) ([]Jump, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.Query(
+		"SELECT "+jumpColumns+" FROM jumps WHERE session = ? ORDER BY id DESC LIMIT ?",
+		session, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jumps: %w", err)
+	}
+	defer rows.Close()
+
+	var jumps []Jump
+	for rows.Next() {
+		jump, err := scanJump(rows)
+		if err != nil {
+			return nil, err
+		}
+		jumps = append(jumps, *jump)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate jumps: %w", err)
+	}
+	return jumps, nil
+}
+
+func scanJump(row rowScanner) (*Jump, error) {
+	var jump Jump
+	err := row.Scan(&jump.ID, &jump.Session, &jump.Shortcut, &jump.From, &jump.Path, &jump.Undone, &jump.At)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan jump: %w", err)
+	}
+	return &jump, nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJumps_BackAndForward(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	record := func(session, name, from, path string) {
+		t.Helper()
+		now = now.Add(time.Minute)
+		if err := s.RecordJump(Jump{Session: session, Shortcut: name, From: from, Path: path, At: now}); err != nil {
+			t.Fatalf("failed to record jump: %v", err)
+		}
+	}
+	record("1", "api", "/home", "/src/api")
+	record("1", "web", "/src/api", "/src/web")
+	record("2", "ops", "/home", "/ops")
+
+	back, err := s.Back("1")
+	if err != nil || back.From != "/src/api" {
+		t.Fatalf("expected back to /src/api, got %+v, %v", back, err)
+	}
+	if back, err = s.Back("1"); err != nil || back.From != "/home" {
+		t.Fatalf("expected back to /home, got %+v, %v", back, err)
+	}
+	if _, err := s.Back("1"); err == nil {
+		t.Fatal("expected an error past the first jump")
+	}
+
+	fwd, err := s.Forward("1")
+	if err != nil || fwd.Path != "/src/api" {
+		t.Fatalf("expected forward to /src/api, got %+v, %v", fwd, err)
+	}
+
+	// A new jump drops the one still ahead
+	record("1", "docs", "/src/api", "/docs")
+	if _, err := s.Forward("1"); err == nil {
+		t.Fatal("expected nothing ahead after a new jump")
+	}
+
+	jumps, err := s.Jumps("1", 0)
+	if err != nil {
+		t.Fatalf("failed to list jumps: %v", err)
+	}
+	if len(jumps) != 2 || jumps[0].Shortcut != "docs" || jumps[1].Shortcut != "api" || !jumps[0].At.Equal(now) {
+		t.Fatalf("expected docs then api, got %+v", jumps)
+	}
+	if other, _ := s.Jumps("2", 0); len(other) != 1 {
+		t.Fatalf("expected sessions to be kept apart, got %+v", other)
+	}
+}
+
+func TestJumps_Pruned(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := s.RecordJump(Jump{Session: "old", Shortcut: "api", Path: "/api", At: start}); err != nil {
+		t.Fatal(err)
+	}
+	now := start.Add(jumpRetention + time.Hour)
+	for i := 0; i < maxSessionJumps+5; i++ {
+		if err := s.RecordJump(Jump{Session: "1", Shortcut: "api", Path: "/api", At: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if jumps, _ := s.Jumps("old", 0); len(jumps) != 0 {
+		t.Fatalf("expected jumps past the retention to go, got %d", len(jumps))
+	}
+	if jumps, _ := s.Jumps("1", 0); len(jumps) != maxSessionJumps {
+		t.Fatalf("expected %d jumps kept, got %d", maxSessionJumps, len(jumps))
+	}
+}
+
+func TestLogJump_FoldedOnOpen(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if err := s.AddShortcut("api", "/tmp/api"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	_ = s.Close()
+
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if err := LogJump(dbPath, Jump{Session: "7", Shortcut: "api", From: "/home", Path: "/tmp/api", At: at}); err != nil {
+		t.Fatalf("failed to log jump: %v", err)
+	}
+	if err := LogVisit(dbPath, "api", at); err != nil {
+		t.Fatalf("failed to log visit: %v", err)
+	}
+
+	s, err = NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen storage: %v", err)
+	}
+	defer s.Close()
+
+	sc, err := s.GetShortcut("api")
+	if err != nil || sc.Visits != 2 {
+		t.Fatalf("expected both lines to count as visits, got %+v, %v", sc, err)
+	}
+	back, err := s.Back("7")
+	if err != nil || back.From != "/home" || back.Path != "/tmp/api" {
+		t.Fatalf("expected the logged jump, got %+v, %v", back, err)
+	}
+}
@@ -9,4 +9,57 @@ type Shortcut struct {
 	Tags      []string
 	CreatedAt time.Time
 	UpdatedAt time.Time
-}
\ No newline at end of file
+	// Number of jumps to the shortcut and when the last one happened
+	// (zero if never)
+	Visits        int
+	LastVisitedAt time.Time
+}
+
+// Kinds of journaled operations
+const (
+	OpAdd      = "add"
+	OpDelete   = "delete"
+	OpEditPath = "edit-path"
+	OpEditName = "edit-name"
+	OpTag      = "tag"
+	OpUntag    = "untag"
+	OpRestore  = "restore"
+)
+
+// Operation is a journaled mutation with the shortcut state before and after it.
+// Before is nil for additions, After is nil for deletions.
+type Operation struct {
+	ID        int
+	Kind      string
+	Shortcut  string
+	Before    *Shortcut
+	After     *Shortcut
+	Undone    bool
+	CreatedAt time.Time
+}
+
+// Jump is a cd made through fs in one shell session. Undone jumps are ones
+// fs back went back past, which fs forward can take again.
+type Jump struct {
+	ID       int
+	Session  string
+	Shortcut string
+	From     string // where the shell was
+	Path     string // where it went
+	Undone   bool
+	At       time.Time
+}
+
+// TrashedShortcut is a deleted shortcut waiting in the trash
+type TrashedShortcut struct {
+	Shortcut
+	DeletedAt time.Time
+}
+
+// Backup is a copy of the database in the backup directory
+type Backup struct {
+	ID        string
+	Path      string
+	CreatedAt time.Time
+	Size      int64
+}
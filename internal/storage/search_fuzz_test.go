@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var fuzzShortcuts = []struct{ name, path string }{
+	{"plain", "/srv/plain"},
+	{"100%-done", "/srv/percent"},
+	{"snake_case", "/srv/snake"},
+	{"snakeXcase", "/srv/snake-x"},
+	{`back\slash`, `/srv/back\slash`},
+	{"CamelCase", "/srv/Camel"},
+	{"ünïcode", "/srv/ÜNÏCODE"},
+	{"日本", "/srv/日本語"},
+}
+
+// SQLite's LIKE folds ASCII letters only
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, s)
+}
+
+func FuzzSearchShortcuts(f *testing.F) {
+	for _, seed := range []string{"", "plain", "%", "_", `\`, "100%", "e_c", "CAMEL", "ü", "Ü", "本", "%_%"} {
+		f.Add(seed)
+	}
+
+	s, err := NewSQLiteStorage(filepath.Join(f.TempDir(), "fuzz.db"))
+	if err != nil {
+		f.Fatalf("failed to create storage: %v", err)
+	}
+	f.Cleanup(func() { _ = s.Close() })
+
+	for _, sc := range fuzzShortcuts {
+		if err := s.AddShortcut(sc.name, sc.path); err != nil {
+			f.Fatalf("failed to add %s: %v", sc.name, err)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, query string) {
+		results, err := s.SearchShortcuts(query, nil, "or")
+		if err != nil {
+			t.Fatalf("search %q failed: %v", query, err)
+		}
+
+		got := shortcutNames(results)
+		var want []string
+		needle := asciiLower(query)
+		for _, sc := range fuzzShortcuts {
+			if strings.ContainsRune(query, 0) || !utf8.ValidString(query) {
+				break
+			}
+			if strings.Contains(asciiLower(sc.name), needle) || strings.Contains(asciiLower(sc.path), needle) {
+				want = append(want, sc.name)
+			}
+		}
+		sort.Strings(got)
+		sort.Strings(want)
+
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Fatalf("search %q: got %q, want %q", query, got, want)
+		}
+	})
+}
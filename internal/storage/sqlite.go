@@ -6,12 +6,21 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	_ "modernc.org/sqlite"
 )
 
 type SQLiteStorage struct {
-	db *sql.DB
+	db   *sql.DB
+	path string
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
 // Create a new SQLite storage instance
@@ -33,16 +42,69 @@ func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
 		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
 	}
 
-	storage := &SQLiteStorage{db: db}
+	storage := &SQLiteStorage{db: db, path: dbPath}
 
 	// Initialize tables
-	if err := storage.initTables(); err != nil {
-		return nil, fmt.Errorf("failed to initialize tables: %w", err)
+	if err := storage.migrate(dbPath); err != nil {
+		_ = db.Close()
+		return nil, err
 	}
 
+	// Visits logged by the fast path only feed sorting, so a log that
+	// can't be folded in yet is left for the next open
+	_ = storage.foldVisitLog()
+
 	return storage, nil
 }
 
+// Bump when the schema changes. Existing databases with an older
+// version are backed up before their tables are migrated.
+//
+//	1: operations and trash tables
+//	2: index on shortcut_tags(tag_id)
+//	3: visits and last_visited_at on shortcuts
+//	4: jumps table
+const schemaVersion = 4
+
+func (s *SQLiteStorage) migrate(dbPath string) error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	// Nothing to create on the common path; this keeps `fs go` cheap
+	if version >= schemaVersion {
+		return nil
+	}
+
+	var existing int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'shortcuts'").Scan(&existing)
+	if err != nil {
+		return fmt.Errorf("failed to inspect database: %w", err)
+	}
+	if existing > 0 {
+		if _, err := s.CreateBackup(DefaultBackupDir(dbPath), 0); err != nil {
+			return fmt.Errorf("failed to back up database before migrating: %w", err)
+		}
+	}
+
+	if err := s.initTables(); err != nil {
+		return fmt.Errorf("failed to initialize tables: %w", err)
+	}
+	if err := s.addColumn("shortcuts", "visits", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := s.addColumn("shortcuts", "last_visited_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)); err != nil {
+		return fmt.Errorf("failed to update schema version: %w", err)
+	}
+
+	return nil
+}
+
 func (s *SQLiteStorage) initTables() error {
 	schema := `
 	CREATE TABLE IF NOT EXISTS shortcuts (
@@ -50,7 +112,9 @@ func (s *SQLiteStorage) initTables() error {
 		name TEXT UNIQUE NOT NULL,
 		path TEXT NOT NULL,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		visits INTEGER NOT NULL DEFAULT 0,
+		last_visited_at TIMESTAMP
 	);
 
 	CREATE TABLE IF NOT EXISTS tags (
@@ -65,29 +129,92 @@ func (s *SQLiteStorage) initTables() error {
 		FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
 		PRIMARY KEY (shortcut_id, tag_id)
 	);
+
+	CREATE INDEX IF NOT EXISTS idx_shortcut_tags_tag_id ON shortcut_tags(tag_id);
+
+	CREATE TABLE IF NOT EXISTS operations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		shortcut TEXT NOT NULL,
+		before_state TEXT,
+		after_state TEXT,
+		undone INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS jumps (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		session TEXT NOT NULL,
+		shortcut TEXT NOT NULL,
+		from_path TEXT NOT NULL,
+		path TEXT NOT NULL,
+		undone INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_jumps_session ON jumps(session, id);
+
+	CREATE TABLE IF NOT EXISTS trash (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		state TEXT NOT NULL,
+		deleted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
 	`
 
 	_, err := s.db.Exec(schema)
 	return err
 }
 
-func (s *SQLiteStorage) AddShortcut(name, path string) error {
-	_, err := s.db.Exec(
-		"INSERT INTO shortcuts (name, path) VALUES (?, ?)",
-		name, path,
-	)
+// Add a column to a table created by an older schema, if it's missing
+func (s *SQLiteStorage) addColumn(table, column, definition string) error {
+	var count int
+	err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?",
+		table, column,
+	).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("failed to add shortcut: %w", err)
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
 	}
 	return nil
 }
 
-func (s *SQLiteStorage) GetShortcut(name string) (*Shortcut, error) {
+// Columns read into a Shortcut by scanShortcut, for a table aliased s
+const shortcutColumns = "s.id, s.name, s.path, s.created_at, s.updated_at, s.visits, s.last_visited_at"
+
+func scanShortcut(row rowScanner) (Shortcut, error) {
 	var sc Shortcut
-	err := s.db.QueryRow(
-		"SELECT id, name, path, created_at, updated_at FROM shortcuts WHERE name = ?",
+	var lastVisited sql.NullTime
+	err := row.Scan(&sc.ID, &sc.Name, &sc.Path, &sc.CreatedAt, &sc.UpdatedAt, &sc.Visits, &lastVisited)
+	sc.LastVisitedAt = lastVisited.Time
+	return sc, err
+}
+
+func (s *SQLiteStorage) AddShortcut(name, path string) error {
+	return s.journal(OpAdd, name, func(tx *sql.Tx) (string, error) {
+		_, err := tx.Exec(
+			"INSERT INTO shortcuts (name, path) VALUES (?, ?)",
+			name, path,
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to add shortcut: %w", err)
+		}
+		return name, nil
+	})
+}
+
+func (s *SQLiteStorage) GetShortcut(name string) (*Shortcut, error) {
+	sc, err := scanShortcut(s.db.QueryRow(
+		"SELECT "+shortcutColumns+" FROM shortcuts s WHERE s.name = ?",
 		name,
-	).Scan(&sc.ID, &sc.Name, &sc.Path, &sc.CreatedAt, &sc.UpdatedAt)
+	))
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("shortcut '%s' not found", name)
@@ -101,7 +228,7 @@ func (s *SQLiteStorage) GetShortcut(name string) (*Shortcut, error) {
 
 func (s *SQLiteStorage) ListShortcuts() ([]Shortcut, error) {
 	rows, err := s.db.Query(
-		"SELECT id, name, path, created_at, updated_at FROM shortcuts ORDER BY name",
+		"SELECT " + shortcutColumns + " FROM shortcuts s ORDER BY s.name",
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list shortcuts: %w", err)
@@ -110,8 +237,8 @@ func (s *SQLiteStorage) ListShortcuts() ([]Shortcut, error) {
 
 	var shortcuts []Shortcut
 	for rows.Next() {
-		var sc Shortcut
-		if err := rows.Scan(&sc.ID, &sc.Name, &sc.Path, &sc.CreatedAt, &sc.UpdatedAt); err != nil {
+		sc, err := scanShortcut(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan shortcut: %w", err)
 		}
 		shortcuts = append(shortcuts, sc)
@@ -128,102 +255,105 @@ func (s *SQLiteStorage) ListShortcuts() ([]Shortcut, error) {
 	return shortcuts, nil
 }
 
+// Move a shortcut to the trash. It can be brought back with RestoreShortcut
+// until the trash is emptied or purged.
 func (s *SQLiteStorage) DeleteShortcut(name string) error {
-	result, err := s.db.Exec("DELETE FROM shortcuts WHERE name = ?", name)
-	if err != nil {
-		return fmt.Errorf("failed to delete shortcut: %w", err)
-	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to check rows affected: %w", err)
-	}
-	if rows == 0 {
-		return fmt.Errorf("shortcut '%s' not found", name)
-	}
-
-	return nil
+	return s.journal(OpDelete, name, func(tx *sql.Tx) (string, error) {
+		if err := trashShortcut(tx, name); err != nil {
+			return "", err
+		}
+		return "", nil
+	})
 }
 
 func (s *SQLiteStorage) UpdateShortcutPath(name, newPath string) error {
-	result, err := s.db.Exec(
-		"UPDATE shortcuts SET path = ?, updated_at = CURRENT_TIMESTAMP WHERE name = ?",
-		newPath, name,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to update shortcut path: %w", err)
-	}
+	return s.journal(OpEditPath, name, func(tx *sql.Tx) (string, error) {
+		result, err := tx.Exec(
+			"UPDATE shortcuts SET path = ?, updated_at = CURRENT_TIMESTAMP WHERE name = ?",
+			newPath, name,
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to update shortcut path: %w", err)
+		}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to check rows affected: %w", err)
-	}
-	if rows == 0 {
-		return fmt.Errorf("shortcut '%s' not found", name)
-	}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return "", fmt.Errorf("failed to check rows affected: %w", err)
+		}
+		if rows == 0 {
+			return "", fmt.Errorf("shortcut '%s' not found", name)
+		}
 
-	return nil
+		return name, nil
+	})
 }
 
 func (s *SQLiteStorage) UpdateShortcutName(oldName, newName string) error {
-	// Check if new name already exists
-	var exists int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM shortcuts WHERE name = ?", newName).Scan(&exists)
-	if err != nil {
-		return fmt.Errorf("failed to check for existing shortcut: %w", err)
-	}
-	if exists > 0 {
-		return fmt.Errorf("shortcut '%s' already exists", newName)
-	}
+	return s.journal(OpEditName, oldName, func(tx *sql.Tx) (string, error) {
+		// Check if new name already exists
+		var exists int
+		err := tx.QueryRow("SELECT COUNT(*) FROM shortcuts WHERE name = ?", newName).Scan(&exists)
+		if err != nil {
+			return "", fmt.Errorf("failed to check for existing shortcut: %w", err)
+		}
+		if exists > 0 {
+			return "", fmt.Errorf("shortcut '%s' already exists", newName)
+		}
 
-	// Update the name
-	result, err := s.db.Exec(
-		"UPDATE shortcuts SET name = ?, updated_at = CURRENT_TIMESTAMP WHERE name = ?",
-		newName, oldName,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to update shortcut name: %w", err)
-	}
+		// Update the name
+		result, err := tx.Exec(
+			"UPDATE shortcuts SET name = ?, updated_at = CURRENT_TIMESTAMP WHERE name = ?",
+			newName, oldName,
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to update shortcut name: %w", err)
+		}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to check rows affected: %w", err)
-	}
-	if rows == 0 {
-		return fmt.Errorf("shortcut '%s' not found", oldName)
-	}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return "", fmt.Errorf("failed to check rows affected: %w", err)
+		}
+		if rows == 0 {
+			return "", fmt.Errorf("shortcut '%s' not found", oldName)
+		}
 
-	return nil
+		return newName, nil
+	})
 }
 
 func (s *SQLiteStorage) AddTags(shortcutName string, tags []string) error {
-	// Get shortcut ID
-	var shortcutID int
-	err := s.db.QueryRow("SELECT id FROM shortcuts WHERE name = ?", shortcutName).Scan(&shortcutID)
-	if err == sql.ErrNoRows {
-		return fmt.Errorf("shortcut '%s' not found", shortcutName)
-	}
-	if err != nil {
-		return fmt.Errorf("failed to find shortcut: %w", err)
-	}
+	return s.journal(OpTag, shortcutName, func(tx *sql.Tx) (string, error) {
+		shortcutID, err := findShortcutID(tx, shortcutName)
+		if err != nil {
+			return "", err
+		}
+
+		if err := linkTags(tx, shortcutID, tags); err != nil {
+			return "", err
+		}
+
+		return shortcutName, nil
+	})
+}
 
-	// Add each tag
+// Insert missing tags and link them to a shortcut
+func linkTags(q queryer, shortcutID int, tags []string) error {
 	for _, tag := range tags {
 		// Insert tag if it doesn't exist
-		_, err := s.db.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?)", tag)
+		_, err := q.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?)", tag)
 		if err != nil {
 			return fmt.Errorf("failed to insert tag: %w", err)
 		}
 
 		// Get tag ID
 		var tagID int
-		err = s.db.QueryRow("SELECT id FROM tags WHERE name = ?", tag).Scan(&tagID)
+		err = q.QueryRow("SELECT id FROM tags WHERE name = ?", tag).Scan(&tagID)
 		if err != nil {
 			return fmt.Errorf("failed to get tag ID: %w", err)
 		}
 
 		// Link shortcut and tag
-		_, err = s.db.Exec(
+		_, err = q.Exec(
 			"INSERT OR IGNORE INTO shortcut_tags (shortcut_id, tag_id) VALUES (?, ?)",
 			shortcutID, tagID,
 		)
@@ -236,48 +366,55 @@ func (s *SQLiteStorage) AddTags(shortcutName string, tags []string) error {
 }
 
 func (s *SQLiteStorage) RemoveTags(shortcutName string, tags []string) error {
-	// Get shortcut ID
-	var shortcutID int
-	err := s.db.QueryRow("SELECT id FROM shortcuts WHERE name = ?", shortcutName).Scan(&shortcutID)
-	if err == sql.ErrNoRows {
-		return fmt.Errorf("shortcut '%s' not found", shortcutName)
-	}
-	if err != nil {
-		return fmt.Errorf("failed to find shortcut: %w", err)
-	}
-
-	// Remove each tag
-	for _, tag := range tags {
-		_, err := s.db.Exec(`
-			DELETE FROM shortcut_tags 
-			WHERE shortcut_id = ? 
-			AND tag_id = (SELECT id FROM tags WHERE name = ?)
-		`, shortcutID, tag)
+	return s.journal(OpUntag, shortcutName, func(tx *sql.Tx) (string, error) {
+		shortcutID, err := findShortcutID(tx, shortcutName)
 		if err != nil {
-			return fmt.Errorf("failed to remove tag: %w", err)
+			return "", err
 		}
-	}
 
-	return nil
+		// Remove each tag
+		for _, tag := range tags {
+			_, err := tx.Exec(`
+				DELETE FROM shortcut_tags 
+				WHERE shortcut_id = ? 
+				AND tag_id = (SELECT id FROM tags WHERE name = ?)
+			`, shortcutID, tag)
+			if err != nil {
+				return "", fmt.Errorf("failed to remove tag: %w", err)
+			}
+		}
+
+		return shortcutName, nil
+	})
 }
 
 func (s *SQLiteStorage) RemoveAllTags(shortcutName string) error {
-	// Get shortcut ID
+	return s.journal(OpUntag, shortcutName, func(tx *sql.Tx) (string, error) {
+		shortcutID, err := findShortcutID(tx, shortcutName)
+		if err != nil {
+			return "", err
+		}
+
+		_, err = tx.Exec("DELETE FROM shortcut_tags WHERE shortcut_id = ?", shortcutID)
+		if err != nil {
+			return "", fmt.Errorf("failed to remove all tags: %w", err)
+		}
+
+		return shortcutName, nil
+	})
+}
+
+// Look up a shortcut ID by name
+func findShortcutID(q queryer, name string) (int, error) {
 	var shortcutID int
-	err := s.db.QueryRow("SELECT id FROM shortcuts WHERE name = ?", shortcutName).Scan(&shortcutID)
+	err := q.QueryRow("SELECT id FROM shortcuts WHERE name = ?", name).Scan(&shortcutID)
 	if err == sql.ErrNoRows {
-		return fmt.Errorf("shortcut '%s' not found", shortcutName)
-	}
-	if err != nil {
-		return fmt.Errorf("failed to find shortcut: %w", err)
+		return 0, fmt.Errorf("shortcut '%s' not found", name)
 	}
-
-	_, err = s.db.Exec("DELETE FROM shortcut_tags WHERE shortcut_id = ?", shortcutID)
 	if err != nil {
-		return fmt.Errorf("failed to remove all tags: %w", err)
+		return 0, fmt.Errorf("failed to find shortcut: %w", err)
 	}
-
-	return nil
+	return shortcutID, nil
 }
 
 func (s *SQLiteStorage) GetShortcutTags(shortcutName string) ([]string, error) {
@@ -306,11 +443,72 @@ func (s *SQLiteStorage) GetShortcutTags(shortcutName string) ([]string, error) {
 	return tags, nil
 }
 
+// Keep IN (...) lists well under SQLite's bound parameter limit
+// (32766 in current builds, 999 in older ones)
+const maxQueryParams = 500
+
 func (s *SQLiteStorage) attachTagsToShortcuts(shortcuts []Shortcut) error {
 	if len(shortcuts) == 0 {
 		return nil
 	}
 
+	tagsByShortcutID := make(map[int][]string, len(shortcuts))
+
+	// One pass over every link beats many IN batches once the
+	// result set covers most of the table
+	fullScan := false
+	if len(shortcuts) > maxQueryParams {
+		var total int
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM shortcuts").Scan(&total); err != nil {
+			return fmt.Errorf("failed to count shortcuts: %w", err)
+		}
+		fullScan = len(shortcuts)*2 >= total
+	}
+
+	if fullScan {
+		if err := s.fetchAllTags(shortcuts, tagsByShortcutID); err != nil {
+			return err
+		}
+	} else {
+		for start := 0; start < len(shortcuts); start += maxQueryParams {
+			end := min(start+maxQueryParams, len(shortcuts))
+			if err := s.fetchTags(shortcuts[start:end], tagsByShortcutID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range shortcuts {
+		shortcuts[i].Tags = tagsByShortcutID[shortcuts[i].ID]
+	}
+
+	return nil
+}
+
+func (s *SQLiteStorage) fetchAllTags(shortcuts []Shortcut, tagsByShortcutID map[int][]string) error {
+	wanted := make(map[int]struct{}, len(shortcuts))
+	for _, sc := range shortcuts {
+		wanted[sc.ID] = struct{}{}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT st.shortcut_id, t.name
+		FROM shortcut_tags st
+		JOIN tags t ON t.id = st.tag_id
+		ORDER BY t.name
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tags for shortcuts: %w", err)
+	}
+
+	return scanShortcutTags(rows, func(shortcutID int, tag string) {
+		if _, ok := wanted[shortcutID]; ok {
+			tagsByShortcutID[shortcutID] = append(tagsByShortcutID[shortcutID], tag)
+		}
+	})
+}
+
+func (s *SQLiteStorage) fetchTags(shortcuts []Shortcut, tagsByShortcutID map[int][]string) error {
 	placeholders := make([]string, len(shortcuts))
 	args := make([]interface{}, len(shortcuts))
 	for i, sc := range shortcuts {
@@ -330,26 +528,28 @@ func (s *SQLiteStorage) attachTagsToShortcuts(shortcuts []Shortcut) error {
 	if err != nil {
 		return fmt.Errorf("failed to fetch tags for shortcuts: %w", err)
 	}
+
+	return scanShortcutTags(rows, func(shortcutID int, tag string) {
+		tagsByShortcutID[shortcutID] = append(tagsByShortcutID[shortcutID], tag)
+	})
+}
+
+func scanShortcutTags(rows *sql.Rows, add func(shortcutID int, tag string)) error {
 	defer rows.Close()
 
-	tagsByShortcutID := make(map[int][]string, len(shortcuts))
 	for rows.Next() {
 		var shortcutID int
 		var tag string
 		if err := rows.Scan(&shortcutID, &tag); err != nil {
 			return fmt.Errorf("failed to scan shortcut tag: %w", err)
 		}
-		tagsByShortcutID[shortcutID] = append(tagsByShortcutID[shortcutID], tag)
+		add(shortcutID, tag)
 	}
 
 	if err := rows.Err(); err != nil {
 		return fmt.Errorf("failed to iterate shortcut tags: %w", err)
 	}
 
-	for i := range shortcuts {
-		shortcuts[i].Tags = tagsByShortcutID[shortcuts[i].ID]
-	}
-
 	return nil
 }
 
@@ -364,47 +564,46 @@ func (s *SQLiteStorage) SearchShortcuts(query string, tags []string, tagOp strin
 		return nil, fmt.Errorf("invalid tag operator '%s': expected 'or' or 'and'", tagOp)
 	}
 
-	sqlQuery := `
-		SELECT s.id, s.name, s.path, s.created_at, s.updated_at
-		FROM shortcuts s
-	`
+	// SQLite stops reading a LIKE pattern at NUL, which would match every
+	// row, and decodes broken UTF-8 loosely enough to match unrelated
+	// characters. Neither can be typed as part of a name or path.
+	if strings.ContainsRune(query, 0) || !utf8.ValidString(query) {
+		return nil, nil
+	}
+
+	sqlQuery := "SELECT " + shortcutColumns + " FROM shortcuts s"
 
 	var conditions []string
 	var args []interface{}
 
-	// Add tag filtering
+	// Add tag filtering. Resolving tags to shortcut ids first lets
+	// SQLite walk idx_shortcut_tags_tag_id instead of probing every row
 	if len(tags) > 0 {
+		unique := uniqueTags(tags)
+		placeholders := make([]string, len(unique))
+		for i, tag := range unique {
+			placeholders[i] = "?"
+			args = append(args, tag)
+		}
+
+		tagFilter := fmt.Sprintf(`s.id IN (
+			SELECT st.shortcut_id
+			FROM shortcut_tags st
+			JOIN tags t ON t.id = st.tag_id
+			WHERE t.name IN (%s)`, strings.Join(placeholders, ","))
 		if tagOp == "and" {
-			for range tags {
-				conditions = append(conditions, `EXISTS (
-					SELECT 1
-					FROM shortcut_tags st
-					JOIN tags t ON t.id = st.tag_id
-					WHERE st.shortcut_id = s.id AND t.name = ?
-				)`)
-			}
-			for _, tag := range tags {
-				args = append(args, tag)
-			}
-		} else {
-			placeholders := make([]string, len(tags))
-			for i, tag := range tags {
-				placeholders[i] = "?"
-				args = append(args, tag)
-			}
-			conditions = append(conditions, fmt.Sprintf(`EXISTS (
-				SELECT 1
-				FROM shortcut_tags st
-				JOIN tags t ON t.id = st.tag_id
-				WHERE st.shortcut_id = s.id AND t.name IN (%s)
-			)`, strings.Join(placeholders, ",")))
+			tagFilter += `
+			GROUP BY st.shortcut_id
+			HAVING COUNT(*) = ?`
+			args = append(args, len(unique))
 		}
+		conditions = append(conditions, tagFilter+"\n\t\t)")
 	}
 
 	// Add text search
 	if query != "" {
-		conditions = append(conditions, "(s.name LIKE ? OR s.path LIKE ?)")
-		searchPattern := "%" + query + "%"
+		conditions = append(conditions, `(s.name LIKE ? ESCAPE '\' OR s.path LIKE ? ESCAPE '\')`)
+		searchPattern := "%" + escapeLike(query) + "%"
 		args = append(args, searchPattern, searchPattern)
 	}
 
@@ -424,8 +623,8 @@ func (s *SQLiteStorage) SearchShortcuts(query string, tags []string, tagOp strin
 
 	var shortcuts []Shortcut
 	for rows.Next() {
-		var sc Shortcut
-		if err := rows.Scan(&sc.ID, &sc.Name, &sc.Path, &sc.CreatedAt, &sc.UpdatedAt); err != nil {
+		sc, err := scanShortcut(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan shortcut: %w", err)
 		}
 		shortcuts = append(shortcuts, sc)
@@ -442,6 +641,28 @@ func (s *SQLiteStorage) SearchShortcuts(query string, tags []string, tagOp strin
 	return shortcuts, nil
 }
 
+// Match %, _ and \ literally in a LIKE pattern using ESCAPE '\'
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func uniqueTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	unique := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		unique = append(unique, tag)
+	}
+	return unique
+}
+
 func (s *SQLiteStorage) Close() error {
+	// A stale cache only costs `fs go` its fast path, so don't fail Close
+	_ = s.refreshCache()
 	return s.db.Close()
 }
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -214,3 +215,83 @@ func TestDeleteShortcut_CascadesShortcutTags(t *testing.T) {
 		t.Fatalf("expected 0 rows in shortcut_tags after deleting shortcut, got %d", links)
 	}
 }
+
+func TestSearchShortcuts_AttachesTagsBeyondParamLimit(t *testing.T) {
+	s := newBenchStorage(t, 3000)
+
+	cases := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "full scan", query: "", want: 3000},
+		{name: "chunked", query: "proj-000", want: 999},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			results, err := s.SearchShortcuts(c.query, nil, "or")
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+			if len(results) != c.want {
+				t.Fatalf("expected %d results, got %d", c.want, len(results))
+			}
+
+			for _, sc := range results {
+				if len(sc.Tags) == 0 || len(sc.Tags) > 2 {
+					t.Fatalf("expected 1-2 tags on %s, got %v", sc.Name, sc.Tags)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchShortcuts_TagOpAndIgnoresDuplicateTags(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.AddTags("cli", []string{"go"}); err != nil {
+		t.Fatalf("failed to add tags: %v", err)
+	}
+
+	results, err := s.SearchShortcuts("", []string{"go", "go"}, "and")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected cli to match, got %v", results)
+	}
+}
+
+func TestSearchShortcuts_EscapesLikeWildcards(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	for name, path := range map[string]string{
+		"100%-done":  "/tmp/percent",
+		"snake_case": "/tmp/snake",
+		"snakeXcase": "/tmp/snake-x",
+	} {
+		if err := s.AddShortcut(name, path); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+	}
+
+	cases := map[string][]string{
+		"%":        {"100%-done"},
+		"e_c":      {"snake_case"},
+		"%\x00":    nil,
+		"\xe3\x9c": nil,
+	}
+	for query, want := range cases {
+		results, err := s.SearchShortcuts(query, nil, "or")
+		if err != nil {
+			t.Fatalf("search %q failed: %v", query, err)
+		}
+		if got := shortcutNames(results); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("search %q: got %v, want %v", query, got, want)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package storage
 
+import "time"
+
 type Storage interface {
 	// Shortcut operations
 	AddShortcut(name, path string) error
@@ -8,6 +10,7 @@ type Storage interface {
 	DeleteShortcut(name string) error
 	UpdateShortcutPath(name, newPath string) error
 	UpdateShortcutName(oldName, newName string) error
+	RecordVisit(name string) error
 
 	// Tag operations
 	AddTags(shortcutName string, tags []string) error
@@ -16,6 +19,26 @@ type Storage interface {
 	GetShortcutTags(shortcutName string) ([]string, error)
 	SearchShortcuts(query string, tags []string, tagOp string) ([]Shortcut, error)
 
+	// Trash operations
+	ListTrash() ([]TrashedShortcut, error)
+	RestoreShortcut(name string) error
+	EmptyTrash() (int, error)
+	PurgeTrash(retention time.Duration) (int, error)
+
+	// Write a backup into dir, keeping at most keep backups
+	CreateBackup(dir string, keep int) (*Backup, error)
+
+	// Operation history
+	Undo() (*Operation, error)
+	Redo() (*Operation, error)
+	History(limit int) ([]Operation, error)
+
+	// Jumps per shell session
+	RecordJump(jump Jump) error
+	Back(session string) (*Jump, error)
+	Forward(session string) (*Jump, error)
+	Jumps(session string, limit int) ([]Jump, error)
+
 	// Close the database
 	Close() error
 }
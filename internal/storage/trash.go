@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Move a shortcut and its tags into the trash
+func trashShortcut(tx *sql.Tx, name string) error {
+	snap, err := loadSnapshot(tx, name)
+	if err != nil {
+		return err
+	}
+	if snap == nil {
+		return fmt.Errorf("shortcut '%s' not found", name)
+	}
+
+	state, err := encodeSnapshot(snap)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("INSERT INTO trash (name, state) VALUES (?, ?)", name, state); err != nil {
+		return fmt.Errorf("failed to move shortcut to trash: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM shortcuts WHERE name = ?", name); err != nil {
+		return fmt.Errorf("failed to delete shortcut: %w", err)
+	}
+
+	return nil
+}
+
+// Take the most recently trashed entry for a name out of the trash. Once the
+// trash is emptied or purged the shortcut is gone for good, so undo and redo
+// can't bring it back from the journal either.
+func untrashShortcut(tx *sql.Tx, name string) error {
+	result, err := tx.Exec(`
+		DELETE FROM trash
+		WHERE id = (SELECT id FROM trash WHERE name = ? ORDER BY id DESC LIMIT 1)
+	`, name)
+	if err != nil {
+		return fmt.Errorf("failed to remove shortcut from trash: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("shortcut '%s' was permanently deleted from the trash", name)
+	}
+	return nil
+}
+
+// List trashed shortcuts, most recently deleted first
+func (s *SQLiteStorage) ListTrash() ([]TrashedShortcut, error) {
+	rows, err := s.db.Query("SELECT id, state, deleted_at FROM trash ORDER BY id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	defer rows.Close()
+
+	var trashed []TrashedShortcut
+	for rows.Next() {
+		var id int
+		var state sql.NullString
+		var deletedAt time.Time
+		if err := rows.Scan(&id, &state, &deletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trashed shortcut: %w", err)
+		}
+
+		snap, err := decodeSnapshot(state)
+		if err != nil {
+			return nil, err
+		}
+
+		sc := snap.shortcut()
+		sc.ID = id
+		trashed = append(trashed, TrashedShortcut{Shortcut: *sc, DeletedAt: deletedAt})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate trash: %w", err)
+	}
+
+	return trashed, nil
+}
+
+// Restore the most recently trashed shortcut with the given name
+func (s *SQLiteStorage) RestoreShortcut(name string) error {
+	return s.journal(OpRestore, name, func(tx *sql.Tx) (string, error) {
+		var state sql.NullString
+		err := tx.QueryRow(
+			"SELECT state FROM trash WHERE name = ? ORDER BY id DESC LIMIT 1",
+			name,
+		).Scan(&state)
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("shortcut '%s' not found in trash", name)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read trash: %w", err)
+		}
+
+		snap, err := decodeSnapshot(state)
+		if err != nil {
+			return "", err
+		}
+
+		if err := applySnapshot(tx, nil, snap); err != nil {
+			return "", err
+		}
+
+		return name, nil
+	})
+}
+
+// Permanently delete everything in the trash
+func (s *SQLiteStorage) EmptyTrash() (int, error) {
+	result, err := s.db.Exec("DELETE FROM trash")
+	if err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	return int(rows), nil
+}
+
+// Permanently delete trashed shortcuts older than the retention period
+func (s *SQLiteStorage) PurgeTrash(retention time.Duration) (int, error) {
+	result, err := s.db.Exec(
+		"DELETE FROM trash WHERE deleted_at < datetime('now', ?)",
+		fmt.Sprintf("-%d seconds", int64(retention.Seconds())),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	return int(rows), nil
+}
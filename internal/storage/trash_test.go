@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeleteShortcut_MovesToTrashWithTags(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.AddTags("cli", []string{"go", "proj"}); err != nil {
+		t.Fatalf("failed to add tags: %v", err)
+	}
+	if err := s.DeleteShortcut("cli"); err != nil {
+		t.Fatalf("DeleteShortcut returned error: %v", err)
+	}
+
+	trashed, err := s.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash returned error: %v", err)
+	}
+	if len(trashed) != 1 {
+		t.Fatalf("expected 1 trashed shortcut, got %d", len(trashed))
+	}
+	if trashed[0].Name != "cli" || trashed[0].Path != "/tmp/cli" {
+		t.Fatalf("unexpected trashed shortcut %+v", trashed[0])
+	}
+	if !slices.Equal(trashed[0].Tags, []string{"go", "proj"}) {
+		t.Fatalf("expected tags to be kept in trash, got %v", trashed[0].Tags)
+	}
+}
+
+func TestRestoreShortcut(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.AddTags("cli", []string{"go"}); err != nil {
+		t.Fatalf("failed to add tags: %v", err)
+	}
+	if err := s.DeleteShortcut("cli"); err != nil {
+		t.Fatalf("DeleteShortcut returned error: %v", err)
+	}
+
+	if err := s.RestoreShortcut("cli"); err != nil {
+		t.Fatalf("RestoreShortcut returned error: %v", err)
+	}
+
+	tags, err := s.GetShortcutTags("cli")
+	if err != nil {
+		t.Fatalf("GetShortcutTags returned error: %v", err)
+	}
+	if !slices.Equal(tags, []string{"go"}) {
+		t.Fatalf("expected restored tags [go], got %v", tags)
+	}
+
+	trashed, err := s.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash returned error: %v", err)
+	}
+	if len(trashed) != 0 {
+		t.Fatalf("expected trash to be empty after restore, got %d", len(trashed))
+	}
+}
+
+func TestRestoreShortcut_NameTaken(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.DeleteShortcut("cli"); err != nil {
+		t.Fatalf("DeleteShortcut returned error: %v", err)
+	}
+	if err := s.AddShortcut("cli", "/tmp/other"); err != nil {
+		t.Fatalf("failed to re-add shortcut: %v", err)
+	}
+
+	if err := s.RestoreShortcut("cli"); err == nil {
+		t.Fatal("expected error restoring over an existing shortcut")
+	}
+}
+
+func TestUndoDelete_RemovesFromTrash(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.DeleteShortcut("cli"); err != nil {
+		t.Fatalf("DeleteShortcut returned error: %v", err)
+	}
+	if _, err := s.Undo(); err != nil {
+		t.Fatalf("Undo returned error: %v", err)
+	}
+
+	trashed, err := s.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash returned error: %v", err)
+	}
+	if len(trashed) != 0 {
+		t.Fatalf("expected undo to take the shortcut out of the trash, got %d", len(trashed))
+	}
+}
+
+func TestPurgeTrash_OnlyRemovesExpired(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	for _, name := range []string{"old", "new"} {
+		if err := s.AddShortcut(name, "/tmp/"+name); err != nil {
+			t.Fatalf("failed to add shortcut: %v", err)
+		}
+		if err := s.DeleteShortcut(name); err != nil {
+			t.Fatalf("DeleteShortcut returned error: %v", err)
+		}
+	}
+	if _, err := s.db.Exec("UPDATE trash SET deleted_at = datetime('now', '-40 days') WHERE name = 'old'"); err != nil {
+		t.Fatalf("failed to age trash entry: %v", err)
+	}
+
+	purged, err := s.PurgeTrash(30 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("PurgeTrash returned error: %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("expected 1 purged shortcut, got %d", purged)
+	}
+
+	trashed, err := s.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash returned error: %v", err)
+	}
+	if len(trashed) != 1 || trashed[0].Name != "new" {
+		t.Fatalf("expected only 'new' left in trash, got %+v", trashed)
+	}
+
+	n, err := s.EmptyTrash()
+	if err != nil || n != 1 {
+		t.Fatalf("expected EmptyTrash to delete 1 entry, got %d (%v)", n, err)
+	}
+}
+
+func TestUndoDelete_RefusesOnceTrashIsEmptied(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+
+	if err := s.AddShortcut("cli", "/tmp/cli"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.DeleteShortcut("cli"); err != nil {
+		t.Fatalf("DeleteShortcut returned error: %v", err)
+	}
+	if _, err := s.EmptyTrash(); err != nil {
+		t.Fatalf("EmptyTrash returned error: %v", err)
+	}
+
+	if _, err := s.Undo(); err == nil || !strings.Contains(err.Error(), "permanently deleted") {
+		t.Fatalf("expected undo to refuse a permanently deleted shortcut, got %v", err)
+	}
+	if _, err := s.GetShortcut("cli"); err == nil {
+		t.Fatal("expected the shortcut to stay deleted")
+	}
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The `fs go` fast path never opens SQLite, so it appends its visits to a
+// log next to the database instead:
+//
+//	<unix seconds><TAB>name
+//	<unix seconds><TAB>name<TAB>session<TAB>from<TAB>path
+//
+// The second form is also a jump of a shell session. The next open folds
+// the log into the visits columns and the jumps table. Visits only feed
+// sorting and jumps fs back, so the log is best effort: a line that is
+// lost or malformed costs one count or one step, never a command.
+
+// Timestamps stored the way CURRENT_TIMESTAMP writes them, so they sort as text
+const timestampLayout = "2006-01-02 15:04:05"
+
+// Size past which the fast path leaves `fs go` to the database, whose open
+// folds the log, so a shell that only ever jumps doesn't grow it for good.
+// About a thousand jumps.
+const maxVisitLog = 64 << 10
+
+// Where the visit log for dbPath lives
+func VisitLogPath(dbPath string) string {
+	return dbPath + ".visits"
+}
+
+// Append a visit to name at the given time to the visit log
+func LogVisit(dbPath, name string, at time.Time) error {
+	return appendVisitLog(dbPath, at, name)
+}
+
+// Append a jump, and a visit to its shortcut, to the visit log
+func LogJump(dbPath string, jump Jump) error {
+	return appendVisitLog(dbPath, jump.At, jump.Shortcut, jump.Session, jump.From, jump.Path)
+}
+
+// Whether the visit log has grown past maxVisitLog and should be folded
+func visitLogFull(dbPath string) bool {
+	info, err := os.Stat(VisitLogPath(dbPath))
+	return err == nil && info.Size() >= maxVisitLog
+}
+
+func appendVisitLog(dbPath string, at time.Time, fields ...string) error {
+	for _, field := range fields {
+		if strings.ContainsAny(field, "\t\n") {
+			return nil
+		}
+	}
+
+	f, err := os.OpenFile(VisitLogPath(dbPath), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open visit log: %w", err)
+	}
+
+	_, err = fmt.Fprintf(f, "%d\t%s\n", at.Unix(), strings.Join(fields, "\t"))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write visit log: %w", err)
+	}
+	return nil
+}
+
+// Count a jump to name
+func (s *SQLiteStorage) RecordVisit(name string) error {
+	result, err := s.db.Exec(
+		"UPDATE shortcuts SET visits = visits + 1, last_visited_at = CURRENT_TIMESTAMP WHERE name = ?",
+		name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record visit: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("shortcut '%s' not found", name)
+	}
+	return nil
+}
+
+// Apply the visit log to the database and remove it. The log is renamed
+// first so visits appended meanwhile start a new one; a renamed log left
+// by a failed fold is retried before a new one is claimed.
+//
+// The fold holds the database's write lock from before it looks at the log
+// until it commits, so two processes never fold the same lines: the second
+// finds the database locked and leaves the log for a later open. The log is
+// removed before the commit, so a failed commit loses its visits rather than
+// letting a retry count them twice.
+func (s *SQLiteStorage) foldVisitLog() error {
+	logPath := VisitLogPath(s.path)
+	folding := logPath + ".folding"
+	if !fileExists(logPath) && !fileExists(folding) {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Writing nothing still takes the write lock, which a deferred
+	// transaction otherwise only takes at its first real write
+	if _, err := tx.Exec("UPDATE shortcuts SET visits = visits WHERE 0"); err != nil {
+		return fmt.Errorf("failed to lock database for the visit log: %w", err)
+	}
+
+	if !fileExists(folding) {
+		if !fileExists(logPath) {
+			return nil
+		}
+		if err := os.Rename(logPath, folding); err != nil {
+			return fmt.Errorf("failed to claim visit log: %w", err)
+		}
+	}
+
+	f, err := os.Open(folding)
+	if err != nil {
+		return fmt.Errorf("failed to open visit log: %w", err)
+	}
+	defer f.Close()
+
+	sessions := make(map[string]time.Time)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 2 && len(fields) != 5 {
+			continue
+		}
+		secs, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		name := fields[1]
+
+		if len(fields) == 5 && fields[2] != "" {
+			jump := Jump{Session: fields[2], Shortcut: name, From: fields[3], Path: fields[4], At: time.Unix(secs, 0)}
+			if err := insertJump(tx, jump); err != nil {
+				return err
+			}
+			sessions[jump.Session] = jump.At
+		}
+
+		at := time.Unix(secs, 0).UTC().Format(timestampLayout)
+		_, err = tx.Exec(`
+			UPDATE shortcuts
+			SET visits = visits + 1,
+				last_visited_at = MAX(COALESCE(last_visited_at, ''), ?)
+			WHERE name = ?
+		`, at, name)
+		if err != nil {
+			return fmt.Errorf("failed to record visit: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read visit log: %w", err)
+	}
+	for session, at := range sessions {
+		if err := pruneJumps(tx, session, at); err != nil {
+			return err
+		}
+	}
+
+	_ = f.Close()
+	if err := os.Remove(folding); err != nil {
+		return fmt.Errorf("failed to remove visit log: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordVisit(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+	if err := s.AddShortcut("api", "/tmp/api"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := s.RecordVisit("api"); err != nil {
+			t.Fatalf("failed to record visit: %v", err)
+		}
+	}
+
+	sc, err := s.GetShortcut("api")
+	if err != nil {
+		t.Fatalf("failed to get shortcut: %v", err)
+	}
+	if sc.Visits != 2 || sc.LastVisitedAt.IsZero() {
+		t.Fatalf("expected 2 visits with a last visit time, got %d at %v", sc.Visits, sc.LastVisitedAt)
+	}
+
+	if err := s.RecordVisit("missing"); err == nil {
+		t.Fatal("expected an error for an unknown shortcut")
+	}
+}
+
+func TestLogVisit_FoldedOnOpen(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if err := s.AddShortcut("api", "/tmp/api"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	_ = s.Close()
+
+	first := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	last := time.Date(2026, 3, 2, 18, 30, 0, 0, time.UTC)
+	for _, visit := range []struct {
+		name string
+		at   time.Time
+	}{{"api", last}, {"api", first}, {"gone", first}} {
+		if err := LogVisit(dbPath, visit.name, visit.at); err != nil {
+			t.Fatalf("failed to log visit: %v", err)
+		}
+	}
+
+	s, err = NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	sc, err := s.GetShortcut("api")
+	if err != nil {
+		t.Fatalf("failed to get shortcut: %v", err)
+	}
+	if sc.Visits != 2 {
+		t.Fatalf("expected 2 visits from the log, got %d", sc.Visits)
+	}
+	if !sc.LastVisitedAt.Equal(last) {
+		t.Fatalf("expected the latest visit %v to win, got %v", last, sc.LastVisitedAt)
+	}
+	if _, err := os.Stat(VisitLogPath(dbPath)); !os.IsNotExist(err) {
+		t.Fatalf("expected the visit log to be removed, got %v", err)
+	}
+}
+
+func TestUndoDelete_KeepsVisits(t *testing.T) {
+	s := newTestSQLiteStorage(t)
+	if err := s.AddShortcut("api", "/tmp/api"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	if err := s.RecordVisit("api"); err != nil {
+		t.Fatalf("failed to record visit: %v", err)
+	}
+	if err := s.DeleteShortcut("api"); err != nil {
+		t.Fatalf("failed to delete shortcut: %v", err)
+	}
+	if _, err := s.Undo(); err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+
+	sc, err := s.GetShortcut("api")
+	if err != nil {
+		t.Fatalf("failed to get shortcut: %v", err)
+	}
+	if sc.Visits != 1 || sc.LastVisitedAt.IsZero() {
+		t.Fatalf("expected the visit to survive delete and undo, got %d at %v", sc.Visits, sc.LastVisitedAt)
+	}
+}
+
+func TestMigrate_AddsVisitColumns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	// Roll back to a version 2 table
+	for _, stmt := range []string{
+		"ALTER TABLE shortcuts DROP COLUMN visits",
+		"ALTER TABLE shortcuts DROP COLUMN last_visited_at",
+		"INSERT INTO shortcuts (name, path) VALUES ('api', '/tmp/api')",
+		"PRAGMA user_version = 2",
+	} {
+		if _, err := s.db.Exec(stmt); err != nil {
+			t.Fatalf("failed to run %q: %v", stmt, err)
+		}
+	}
+	_ = s.Close()
+
+	s, err = NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to migrate storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	if err := s.RecordVisit("api"); err != nil {
+		t.Fatalf("failed to record visit after migrating: %v", err)
+	}
+	sc, err := s.GetShortcut("api")
+	if err != nil {
+		t.Fatalf("failed to get shortcut: %v", err)
+	}
+	if sc.Visits != 1 {
+		t.Fatalf("expected 1 visit, got %d", sc.Visits)
+	}
+}
+
+// Another process mid-fold holds the write lock, so this open leaves the log
+// alone instead of counting the same visits again
+func TestFoldVisitLog_WaitsForTheWriteLock(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	if err := s.AddShortcut("api", "/tmp/api"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+
+	holder, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open second storage: %v", err)
+	}
+	t.Cleanup(func() { _ = holder.Close() })
+	tx, err := holder.db.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	if _, err := tx.Exec("UPDATE shortcuts SET visits = visits WHERE 0"); err != nil {
+		t.Fatalf("failed to take the write lock: %v", err)
+	}
+
+	if err := LogVisit(dbPath, "api", time.Now()); err != nil {
+		t.Fatalf("failed to log visit: %v", err)
+	}
+	if err := s.foldVisitLog(); err == nil {
+		t.Fatal("expected the fold to give up while the database is locked")
+	}
+	if _, err := os.Stat(VisitLogPath(dbPath)); err != nil {
+		t.Fatalf("expected the visit log to be left for later, got %v", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("failed to roll back: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.foldVisitLog(); err != nil {
+			t.Fatalf("foldVisitLog returned error: %v", err)
+		}
+	}
+	sc, err := s.GetShortcut("api")
+	if err != nil {
+		t.Fatalf("failed to get shortcut: %v", err)
+	}
+	if sc.Visits != 1 {
+		t.Fatalf("expected the visit counted once, got %d", sc.Visits)
+	}
+}
+
+// A full visit log sends fs go to the database, whose open folds it
+func TestLookupCache_MissesOnceTheVisitLogIsFull(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortcuts.db")
+	s, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if err := s.AddShortcut("api", "/tmp/api"); err != nil {
+		t.Fatalf("failed to add shortcut: %v", err)
+	}
+	_ = s.Close()
+
+	for !visitLogFull(dbPath) {
+		if _, ok := LookupCache(dbPath, "api"); !ok {
+			t.Fatal("expected the cache to answer before the visit log is full")
+		}
+		if err := LogVisit(dbPath, "api", time.Now()); err != nil {
+			t.Fatalf("failed to log visit: %v", err)
+		}
+	}
+	if _, ok := LookupCache(dbPath, "api"); ok {
+		t.Fatal("expected a full visit log to miss the cache")
+	}
+
+	s, err = NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen storage: %v", err)
+	}
+	_ = s.Close()
+	if path, ok := LookupCache(dbPath, "api"); !ok || path != "/tmp/api" {
+		t.Fatalf("expected the cache to answer again once the log is folded, got %q (ok=%v)", path, ok)
+	}
+}
@@ -0,0 +1,383 @@
+//go:build !notui
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mikul1999-pixel/fs/internal/storage"
+)
+
+// What the input line is editing, if anything
+type editMode int
+
+const (
+	modeList editMode = iota
+	modeRename
+	modeEditPath
+	modeTags
+	modeConfirmDelete
+)
+
+// editor is the input line shared by the selector and the manager. It edits
+// the shortcuts it was started on in place and reports renames and deletes
+// so the owner can fix up its list.
+type editor struct {
+	store       storage.Storage
+	expandPath  func(string) (string, error)
+	mode        editMode
+	targets     []*storage.Shortcut
+	input       []rune
+	completions []string
+	status      string
+}
+
+// What a finished edit did to the targets
+type editResult struct {
+	renamedFrom string
+	renamedTo   string
+	deleted     []string
+}
+
+func newEditor(store storage.Storage, expandPath func(string) (string, error)) editor {
+	if expandPath == nil {
+		expandPath = filepath.Abs
+	}
+	return editor{store: store, expandPath: expandPath}
+}
+
+func (e editor) active() bool {
+	return e.mode != modeList
+}
+
+// Start editing targets. Actions need a store; rename and edit path work
+// on one shortcut, tags and delete on any number.
+func (e *editor) start(mode editMode, targets []*storage.Shortcut) {
+	if e.store == nil || len(targets) == 0 {
+		return
+	}
+
+	e.status = ""
+	if len(targets) > 1 && (mode == modeRename || mode == modeEditPath) {
+		e.status = "Rename and edit path work on one shortcut at a time"
+		return
+	}
+
+	e.mode = mode
+	e.targets = targets
+	e.completions = nil
+
+	sc := targets[0]
+	switch {
+	case mode == modeRename:
+		e.input = []rune(sc.Name)
+	case mode == modeEditPath:
+		e.input = []rune(sc.Path)
+	case mode == modeTags && len(targets) == 1:
+		e.input = []rune(strings.Join(sc.Tags, " "))
+	default:
+		e.input = nil
+	}
+}
+
+func (e *editor) update(msg tea.KeyMsg) editResult {
+	if e.mode == modeConfirmDelete {
+		if msg.String() == "y" || msg.String() == "Y" {
+			return e.deleteTargets()
+		}
+		e.stop()
+		return editResult{}
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		e.stop()
+	case tea.KeyEnter:
+		return e.submit()
+	case tea.KeyBackspace:
+		if len(e.input) > 0 {
+			e.input = e.input[:len(e.input)-1]
+		}
+	case tea.KeyCtrlU:
+		e.input = nil
+	case tea.KeyTab:
+		if e.mode == modeEditPath {
+			completed, candidates := completeDir(string(e.input), e.expandPath)
+			e.input = []rune(completed)
+			e.completions = candidates
+		}
+	case tea.KeySpace:
+		e.input = append(e.input, ' ')
+	case tea.KeyRunes:
+		e.input = append(e.input, msg.Runes...)
+	}
+
+	return editResult{}
+}
+
+func (e *editor) stop() {
+	e.mode = modeList
+	e.targets = nil
+	e.completions = nil
+}
+
+func (e *editor) submit() editResult {
+	value := strings.TrimSpace(string(e.input))
+
+	var result editResult
+	var err error
+	switch {
+	case e.mode == modeRename:
+		result, err = e.rename(e.targets[0], value)
+	case e.mode == modeEditPath:
+		err = e.editPath(e.targets[0], value)
+	case e.mode == modeTags && len(e.targets) == 1:
+		err = e.retag(e.targets[0], strings.Fields(value))
+	case e.mode == modeTags:
+		err = e.bulkTag(strings.Fields(value))
+	}
+
+	// Keep the input so a typo can be fixed
+	if err != nil {
+		e.status = "Error: " + err.Error()
+		return editResult{}
+	}
+
+	e.stop()
+	return result
+}
+
+func (e *editor) rename(sc *storage.Shortcut, newName string) (editResult, error) {
+	if newName == "" || newName == sc.Name {
+		return editResult{}, nil
+	}
+	if err := e.store.UpdateShortcutName(sc.Name, newName); err != nil {
+		return editResult{}, err
+	}
+
+	e.status = fmt.Sprintf("Renamed %s to %s", sc.Name, newName)
+	result := editResult{renamedFrom: sc.Name, renamedTo: newName}
+	sc.Name = newName
+	return result, nil
+}
+
+func (e *editor) editPath(sc *storage.Shortcut, newPath string) error {
+	if newPath == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+
+	absPath, err := e.expandPath(newPath)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", absPath)
+	}
+	if absPath == sc.Path {
+		return nil
+	}
+
+	if err := e.store.UpdateShortcutPath(sc.Name, absPath); err != nil {
+		return err
+	}
+	sc.Path = absPath
+	e.status = fmt.Sprintf("%s now points to %s", sc.Name, absPath)
+	return nil
+}
+
+// Replace the shortcut's tags with tags, adding and removing the difference
+func (e *editor) retag(sc *storage.Shortcut, tags []string) error {
+	current := make(map[string]struct{}, len(sc.Tags))
+	for _, t := range sc.Tags {
+		current[t] = struct{}{}
+	}
+	wanted := make(map[string]struct{}, len(tags))
+	for _, t := range tags {
+		wanted[t] = struct{}{}
+	}
+
+	var added, removed []string
+	for _, t := range tags {
+		if _, ok := current[t]; !ok {
+			added = append(added, t)
+		}
+	}
+	for _, t := range sc.Tags {
+		if _, ok := wanted[t]; !ok {
+			removed = append(removed, t)
+		}
+	}
+
+	if err := e.applyTags(sc, added, removed); err != nil {
+		return err
+	}
+	e.status = fmt.Sprintf("Tags for %s: %s", sc.Name, strings.Join(sc.Tags, ", "))
+	return nil
+}
+
+// Add tags written as "tag" or "+tag" and remove those written as "-tag"
+// on every target
+func (e *editor) bulkTag(words []string) error {
+	var added, removed []string
+	for _, w := range words {
+		switch {
+		case strings.HasPrefix(w, "-") && len(w) > 1:
+			removed = append(removed, w[1:])
+		case strings.HasPrefix(w, "+") && len(w) > 1:
+			added = append(added, w[1:])
+		case w != "+" && w != "-":
+			added = append(added, w)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	for _, sc := range e.targets {
+		if err := e.applyTags(sc, added, removed); err != nil {
+			return err
+		}
+	}
+
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, "added "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(removed, ", "))
+	}
+	e.status = fmt.Sprintf("Updated %d shortcuts: %s", len(e.targets), strings.Join(parts, "; "))
+	return nil
+}
+
+func (e *editor) applyTags(sc *storage.Shortcut, added, removed []string) error {
+	if len(added) > 0 {
+		if err := e.store.AddTags(sc.Name, added); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		if err := e.store.RemoveTags(sc.Name, removed); err != nil {
+			return err
+		}
+	}
+
+	updated, err := e.store.GetShortcutTags(sc.Name)
+	if err != nil {
+		return err
+	}
+	sc.Tags = updated
+	return nil
+}
+
+func (e *editor) deleteTargets() editResult {
+	var result editResult
+	for _, sc := range e.targets {
+		if err := e.store.DeleteShortcut(sc.Name); err != nil {
+			e.stop()
+			e.status = "Error: " + err.Error()
+			return result
+		}
+		result.deleted = append(result.deleted, sc.Name)
+	}
+
+	if len(result.deleted) == 1 {
+		e.status = fmt.Sprintf("Moved %s to trash (fs undo to restore)", result.deleted[0])
+	} else {
+		e.status = fmt.Sprintf("Moved %d shortcuts to trash (fs undo restores one at a time)", len(result.deleted))
+	}
+	e.stop()
+	return result
+}
+
+func (e editor) render() string {
+	if len(e.targets) == 0 {
+		return ""
+	}
+
+	name := e.targets[0].Name
+	if len(e.targets) > 1 {
+		name = fmt.Sprintf("%d shortcuts", len(e.targets))
+	}
+
+	switch e.mode {
+	case modeRename:
+		return fmt.Sprintf("Rename %s: %s_\n", name, string(e.input))
+	case modeEditPath:
+		line := fmt.Sprintf("Path for %s (tab completes): %s_\n", name, string(e.input))
+		if len(e.completions) > 1 {
+			line += "  " + strings.Join(e.completions, "  ") + "\n"
+		}
+		return line
+	case modeTags:
+		if len(e.targets) > 1 {
+			return fmt.Sprintf("Tags for %s (+add -remove): %s_\n", name, string(e.input))
+		}
+		return fmt.Sprintf("Tags for %s (space separated): %s_\n", name, string(e.input))
+	case modeConfirmDelete:
+		return fmt.Sprintf("Delete %s? (y/N)\n", name)
+	}
+	return ""
+}
+
+// Complete the last element of a directory path. One match is completed
+// in full; several are completed to their common prefix and returned.
+func completeDir(input string, expand func(string) (string, error)) (string, []string) {
+	textDir, prefix := "", input
+	if i := strings.LastIndex(input, "/"); i >= 0 {
+		textDir, prefix = input[:i+1], input[i+1:]
+	}
+
+	lookup := textDir
+	if lookup == "" {
+		lookup = "."
+	}
+	dir, err := expand(lookup)
+	if err != nil {
+		return input, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return input, nil
+	}
+
+	var matches []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || !info.IsDir() {
+			continue
+		}
+		matches = append(matches, name)
+	}
+	sort.Strings(matches)
+
+	switch len(matches) {
+	case 0:
+		return input, nil
+	case 1:
+		return textDir + matches[0] + "/", nil
+	}
+
+	common := []rune(matches[0])
+	for _, match := range matches[1:] {
+		r := []rune(match)
+		n := 0
+		for n < len(common) && n < len(r) && common[n] == r[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	return textDir + string(common), matches
+}
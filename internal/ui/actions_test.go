@@ -0,0 +1,129 @@
+//go:build !notui
+
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+)
+
+func newActionFixture(t *testing.T) (string, *storage.SQLiteStorage, SelectorOptions) {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, dir := range []string{"src/api", "src/app", "src/web", "notes"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := storage.NewSQLiteStorage(filepath.Join(root, "shortcuts.db"))
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	if err := s.AddShortcut("api", filepath.Join(root, "src/api")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddShortcut("web", filepath.Join(root, "src/web")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddTags("api", []string{"go", "proj"}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := SelectorOptions{
+		NoColor: true,
+		Store:   s,
+		ExpandPath: func(path string) (string, error) {
+			if filepath.IsAbs(path) {
+				return filepath.Clean(path), nil
+			}
+			return filepath.Join(root, path), nil
+		},
+	}
+	return root, s, opts
+}
+
+func TestSelectorGolden_ManagementActions(t *testing.T) {
+	root, s, opts := newActionFixture(t)
+	shortcuts, err := s.ListShortcuts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDriver(t, InitialModel(shortcuts, opts)).scrubbing(root, "$TMP")
+
+	d.snapshot("initial").
+		press("r").snapshot("rename prompt").
+		press("ctrl+u", "backend", "enter").snapshot("renamed").
+		press("t", "backspace", "backspace", "backspace", "backspace", "rust", "enter").snapshot("retagged").
+		press("down", "e", "ctrl+u", "src/a", "tab").snapshot("several completions").
+		press("p", "tab").snapshot("one completion").
+		press("enter").snapshot("path edited").
+		press("e", "ctrl+u", "missing", "enter").snapshot("bad path").
+		press("esc", "d").snapshot("confirm delete").
+		press("n").snapshot("delete cancelled").
+		press("d", "y").snapshot("deleted")
+
+	d.assertGolden("management_actions")
+
+	sc, err := s.GetShortcut("backend")
+	if err != nil {
+		t.Fatalf("expected rename to be persisted: %v", err)
+	}
+	tags, err := s.GetShortcutTags("backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tags, ",") != "go,rust" {
+		t.Fatalf("expected tags go,rust, got %v", tags)
+	}
+	if sc.Path != filepath.Join(root, "src/api") {
+		t.Fatalf("expected backend path unchanged, got %s", sc.Path)
+	}
+
+	if _, err := s.GetShortcut("web"); err == nil {
+		t.Fatal("expected web to be deleted")
+	}
+	trash, err := s.ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].Name != "web" || trash[0].Path != filepath.Join(root, "src/app") {
+		t.Fatalf("expected web with its edited path in the trash, got %+v", trash)
+	}
+}
+
+func TestModelUpdate_ActionsNeedStore(t *testing.T) {
+	d := newDriver(t, InitialModel(goldenShortcuts(), SelectorOptions{NoColor: true}))
+	d.press("r")
+
+	if m := d.model.(model); m.edit.active() {
+		t.Fatalf("expected no edit mode without a store, got %v", m.edit.mode)
+	}
+}
+
+func TestModelUpdate_RenameConflictKeepsInput(t *testing.T) {
+	_, s, opts := newActionFixture(t)
+	shortcuts, err := s.ListShortcuts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDriver(t, InitialModel(shortcuts, opts))
+	d.press("r", "ctrl+u", "web", "enter")
+
+	m := d.model.(model)
+	if m.edit.mode != modeRename || string(m.edit.input) != "web" {
+		t.Fatalf("expected to stay in rename with input kept, got mode %v input %q", m.edit.mode, string(m.edit.input))
+	}
+	if !strings.HasPrefix(m.edit.status, "Error:") {
+		t.Fatalf("expected an error status, got %q", m.edit.status)
+	}
+}
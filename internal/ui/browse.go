@@ -0,0 +1,354 @@
+//go:build !notui
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mikul1999-pixel/fs/internal/storage"
+)
+
+// Browsing drills into a shortcut's directory: right opens the folder under
+// the cursor, left goes up and back to the list from the shortcut itself,
+// and enter picks the folder under the cursor as the one to cd to. The
+// first row, "./", stands for the folder being shown.
+
+type browser struct {
+	shortcut  storage.Shortcut
+	dir       string   // folder being shown, the shortcut's path or below it
+	entries   []string // its subfolders
+	shown     []int    // entries passing the filter, best match first
+	matches   [][]int  // matched rune positions per shown entry
+	cursor    int      // row, 0 for "./"
+	offset    int
+	filtering bool
+	filter    []rune
+	err       error
+}
+
+// Start browsing the shortcut under the cursor
+func (m *model) openBrowser() {
+	sc := m.current()
+	if sc == nil || m.multi {
+		return
+	}
+	m.browse = &browser{shortcut: *sc}
+	m.browse.enter(sc.Path, "")
+}
+
+// Show dir, putting the cursor on the subfolder named from when coming up
+func (b *browser) enter(dir, from string) {
+	b.dir = dir
+	b.filter = nil
+	b.filtering = false
+	b.entries, b.err = subdirs(dir)
+	b.refilter()
+	b.cursor = 0
+	for row, i := range b.shown {
+		if b.entries[i] == from {
+			b.cursor = row + 1
+		}
+	}
+}
+
+// Folders in dir, following symlinks, sorted by name
+func subdirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			if e.Type()&os.ModeSymlink == 0 {
+				continue
+			}
+			if info, err := os.Stat(filepath.Join(dir, e.Name())); err != nil || !info.IsDir() {
+				continue
+			}
+		}
+		names = append(names, e.Name())
+	}
+	sort.Slice(names, func(i, j int) bool { return strings.ToLower(names[i]) < strings.ToLower(names[j]) })
+	return names, nil
+}
+
+// Recompute the rows from the filter. Hidden folders only show once the
+// filter starts with a dot.
+func (b *browser) refilter() {
+	pattern := string(b.filter)
+	hidden := strings.HasPrefix(pattern, ".")
+
+	type match struct {
+		entry     int
+		rank      int
+		positions []int
+	}
+	var found []match
+	for i, name := range b.entries {
+		if strings.HasPrefix(name, ".") && !hidden {
+			continue
+		}
+		positions, rank, ok := fuzzyMatch(name, pattern)
+		if ok {
+			found = append(found, match{i, rank, positions})
+		}
+	}
+	sort.SliceStable(found, func(x, y int) bool { return found[x].rank > found[y].rank })
+
+	b.shown, b.matches = b.shown[:0], b.matches[:0]
+	for _, f := range found {
+		b.shown = append(b.shown, f.entry)
+		b.matches = append(b.matches, f.positions)
+	}
+	b.cursor = min(b.cursor, len(b.shown))
+}
+
+// Whether pattern's runes appear in name in order, ignoring case. Prefixes
+// rank above substrings, which rank above scattered matches.
+func fuzzyMatch(name, pattern string) (positions []int, rank int, ok bool) {
+	// Fold case a rune at a time so positions index name's own runes;
+	// strings.ToLower can change the rune count, e.g. for 'İ'
+	nameRunes := []rune(strings.Map(unicode.ToLower, name))
+	patternRunes := []rune(strings.Map(unicode.ToLower, pattern))
+	if len(patternRunes) == 0 {
+		return nil, 0, true
+	}
+
+	for at := range nameRunes {
+		if !runesHavePrefix(nameRunes[at:], patternRunes) {
+			continue
+		}
+		rank = 1
+		if at == 0 {
+			rank = 2
+		}
+		for i := range patternRunes {
+			positions = append(positions, at+i)
+		}
+		return positions, rank, true
+	}
+
+	p := 0
+	for i, r := range nameRunes {
+		if p < len(patternRunes) && r == patternRunes[p] {
+			positions = append(positions, i)
+			p++
+		}
+	}
+	return positions, 0, p == len(patternRunes)
+}
+
+// Folder under the cursor
+func (b *browser) target() string {
+	if b.cursor == 0 || b.cursor > len(b.shown) {
+		return b.dir
+	}
+	return filepath.Join(b.dir, b.entries[b.shown[b.cursor-1]])
+}
+
+// Where dir sits under the shortcut, e.g. "api/internal/ui"
+func (b *browser) title() string {
+	rel, err := filepath.Rel(b.shortcut.Path, b.dir)
+	if err != nil || rel == "." {
+		return b.shortcut.Name
+	}
+	return b.shortcut.Name + "/" + filepath.ToSlash(rel)
+}
+
+func (m model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := m.browse
+	if b.filtering {
+		b.updateFilter(msg)
+		m.scrollBrowse()
+		return m, nil
+	}
+
+	rows := len(b.shown) + 1
+	switch m.keymap.lookup(msg.String()) {
+	case actQuit:
+		// esc clears the filter, then goes back to the list
+		if msg.String() == "esc" {
+			if len(b.filter) > 0 {
+				b.filter = nil
+				b.refilter()
+			} else {
+				m.browse = nil
+			}
+			break
+		}
+		m.quitting = true
+		return m, tea.Quit
+	case actHelp:
+		m.showHelp = true
+	case actUp:
+		b.cursor = max(b.cursor-1, 0)
+	case actDown:
+		b.cursor = min(b.cursor+1, rows-1)
+	case actPageUp:
+		b.cursor = max(b.cursor-m.browseHeight(), 0)
+	case actPageDown:
+		b.cursor = min(b.cursor+m.browseHeight(), rows-1)
+	case actTop:
+		b.cursor = 0
+	case actBottom:
+		b.cursor = rows - 1
+	case actRight:
+		if b.cursor > 0 {
+			b.enter(b.target(), "")
+		}
+	case actLeft:
+		if filepath.Clean(b.dir) == filepath.Clean(b.shortcut.Path) {
+			m.browse = nil
+			break
+		}
+		b.enter(filepath.Dir(b.dir), filepath.Base(b.dir))
+	case actFilter:
+		b.filtering = true
+	case actSelect:
+		sc := b.shortcut
+		sc.Path = b.target()
+		m.selected = &sc
+		m.quitting = true
+		return m, tea.Quit
+	}
+	if m.browse != nil {
+		m.scrollBrowse()
+	}
+	return m, nil
+}
+
+func (b *browser) updateFilter(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		b.filtering = false
+		b.filter = nil
+	case tea.KeyEnter:
+		b.filtering = false
+	case tea.KeyBackspace:
+		if len(b.filter) > 0 {
+			b.filter = b.filter[:len(b.filter)-1]
+		}
+	case tea.KeyCtrlU:
+		b.filter = nil
+	case tea.KeySpace:
+		b.filter = append(b.filter, ' ')
+	case tea.KeyRunes:
+		b.filter = append(b.filter, msg.Runes...)
+	}
+	b.refilter()
+	// The best match is the likely pick
+	if len(b.filter) > 0 && len(b.shown) > 0 {
+		b.cursor = 1
+	}
+}
+
+// Lines above the rows: the title, the filter line when there is one, and
+// a blank line
+func (m model) browseTop() int {
+	if m.browse.filtering || len(m.browse.filter) > 0 {
+		return 3
+	}
+	return 2
+}
+
+// Rows that fit on screen
+func (m model) browseHeight() int {
+	rows := len(m.browse.shown) + 1
+	if m.height <= 0 {
+		return rows
+	}
+	return max(m.height-m.browseTop()-2, 1)
+}
+
+func (m *model) scrollBrowse() {
+	b := m.browse
+	size := m.browseHeight()
+	b.offset = scrollOffset(b.cursor, b.offset, size)
+	b.offset = max(min(b.offset, len(b.shown)+1-size), 0)
+}
+
+func (m model) browseHeader() string {
+	return fmt.Sprintf("Browse %s (%s, %s, %s, %s, %s):",
+		m.browse.title(),
+		m.keymap.describe(actRight, 1)+" to open",
+		m.keymap.describe(actLeft, 1)+" to go up",
+		m.keymap.describe(actFilter, 1)+" to filter",
+		m.keymap.describe(actSelect, 1)+" to select",
+		m.keymap.describe(actQuit, 1)+" to quit")
+}
+
+func (m model) browseView() string {
+	b := m.browse
+	var s strings.Builder
+
+	s.WriteString(m.browseHeader() + "\n")
+	if b.filtering || len(b.filter) > 0 {
+		line := "Filter: " + string(b.filter)
+		if b.filtering {
+			line += "_"
+		}
+		s.WriteString(line + "\n")
+	}
+	s.WriteString("\n")
+
+	rows := len(b.shown) + 1
+	start := b.offset
+	end := min(start+m.browseHeight(), rows)
+	if start > 0 {
+		s.WriteString(m.applyStyle(fmt.Sprintf("  ↑ %d more\n", start), m.styles.more))
+	}
+	for row := start; row < end; row++ {
+		name := "./"
+		if row > 0 {
+			name = m.highlightRunes(b.entries[b.shown[row-1]], b.matches[row-1]) + "/"
+		}
+		if row == b.cursor {
+			s.WriteString(m.applyStyle("> ", m.styles.cursor) + name + "\n")
+		} else {
+			s.WriteString("  " + name + "\n")
+		}
+	}
+	if below := rows - end; below > 0 {
+		s.WriteString(m.applyStyle(fmt.Sprintf("  ↓ %d more\n", below), m.styles.more))
+	}
+
+	switch {
+	case b.err != nil:
+		s.WriteString(m.applyStyle(b.err.Error(), m.styles.more) + "\n")
+	case len(b.entries) == 0:
+		s.WriteString(m.applyStyle("No folders here", m.styles.more) + "\n")
+	case len(b.shown) == 0 && len(b.filter) > 0:
+		s.WriteString(m.applyStyle("No folders match", m.styles.more) + "\n")
+	}
+
+	return m.fit(s.String())
+}
+
+// Highlight the runes of text at positions
+func (m model) highlightRunes(text string, positions []int) string {
+	if len(positions) == 0 || !m.useColor {
+		return text
+	}
+
+	at := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		at[p] = true
+	}
+	var s strings.Builder
+	for i, r := range []rune(text) {
+		if at[i] && !unicode.IsSpace(r) {
+			s.WriteString(m.styles.highlight.Render(string(r)))
+		} else {
+			s.WriteRune(r)
+		}
+	}
+	return s.String()
+}
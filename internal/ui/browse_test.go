@@ -0,0 +1,118 @@
+//go:build !notui
+
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+)
+
+// A project with a few levels of folders, a hidden one and a file
+func browseShortcuts(t *testing.T) (string, []storage.Shortcut) {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, dir := range []string{
+		"api/cmd/fs", "api/internal/storage", "api/internal/ui/testdata", "api/.git", "docs",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "api", "go.mod"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return root, []storage.Shortcut{
+		{Name: "api", Path: filepath.Join(root, "api"), Tags: []string{"go"}},
+		{Name: "docs", Path: filepath.Join(root, "docs")},
+	}
+}
+
+func TestSelectorGolden_Browse(t *testing.T) {
+	root, shortcuts := browseShortcuts(t)
+	d := newDriver(t, InitialModel(shortcuts, SelectorOptions{NoColor: true})).scrubbing(root, "$TMP")
+
+	d.press("right").snapshot("api opened").
+		press("down", "down", "right").snapshot("internal opened").
+		press("left").snapshot("back up on internal").
+		press("l").snapshot("internal again").
+		press("/", "u").snapshot("filtering").
+		press("backspace", "sg").snapshot("fuzzy match").
+		press("esc").snapshot("filter cleared").
+		press("h", "h").snapshot("back to the list").
+		press("down", "l").snapshot("empty folder").
+		press("esc", "up", "l", "/", ".g", "enter").snapshot("hidden folder on a dot")
+
+	d.assertGolden("browse")
+
+	m := d.model.(model)
+	if m.browse == nil || m.browse.target() != filepath.Join(root, "api", ".git") {
+		t.Fatalf("expected the cursor on .git, got %+v", m.browse)
+	}
+}
+
+func TestModelUpdate_BrowseSelectsNestedFolder(t *testing.T) {
+	root, shortcuts := browseShortcuts(t)
+	d := newDriver(t, InitialModel(shortcuts, SelectorOptions{NoColor: true}))
+
+	d.press("l", "/", "int", "enter", "l", "/", "ui", "enter", "enter")
+
+	m := d.model.(model)
+	if !d.done || m.selected == nil {
+		t.Fatal("expected enter to select a folder")
+	}
+	if want := filepath.Join(root, "api", "internal", "ui"); m.selected.Path != want || m.selected.Name != "api" {
+		t.Fatalf("expected api at %s, got %+v", want, m.selected)
+	}
+
+	// "./" picks the folder being shown
+	d = newDriver(t, InitialModel(shortcuts, SelectorOptions{NoColor: true}))
+	d.press("l", "enter")
+	if m := d.model.(model); m.selected == nil || m.selected.Path != shortcuts[0].Path {
+		t.Fatalf("expected the shortcut's own path, got %+v", m.selected)
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name, pattern string
+		rank          int
+		ok            bool
+	}{
+		{"internal", "", 0, true},
+		{"internal", "int", 2, true},
+		{"internal", "NAL", 1, true},
+		{"internal", "itl", 0, true},
+		{"internal", "lit", 0, false},
+	}
+	for _, tt := range tests {
+		_, rank, ok := fuzzyMatch(tt.name, tt.pattern)
+		if rank != tt.rank || ok != tt.ok {
+			t.Errorf("fuzzyMatch(%q, %q) = %d, %v, want %d, %v", tt.name, tt.pattern, rank, ok, tt.rank, tt.ok)
+		}
+	}
+}
+
+// Positions index the name as shown, even where lowercasing a rune makes
+// more of them ('İ' lowers to "i̇")
+func TestFuzzyMatch_PositionsFollowTheName(t *testing.T) {
+	tests := []struct {
+		name, pattern string
+		positions     []int
+	}{
+		{"İstanbul", "tan", []int{2, 3, 4}},
+		{"İstanbul", "ist", []int{0, 1, 2}},
+		{"İstanbul", "sbl", []int{1, 5, 7}},
+	}
+	for _, tt := range tests {
+		positions, _, ok := fuzzyMatch(tt.name, tt.pattern)
+		if !ok || !slices.Equal(positions, tt.positions) {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, %v, want %v", tt.name, tt.pattern, positions, ok, tt.positions)
+		}
+	}
+}
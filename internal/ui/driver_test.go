@@ -0,0 +1,185 @@
+//go:build !notui
+
+package ui
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/")
+
+// Headless bubbletea driver: feeds keys into a model and records View() frames
+type driver struct {
+	t      *testing.T
+	model  tea.Model
+	done   bool
+	color  bool
+	scrub  *strings.Replacer
+	frames strings.Builder
+}
+
+func newDriver(t *testing.T, m tea.Model) *driver {
+	t.Helper()
+
+	d := &driver{t: t, model: m}
+	d.exec(m.Init())
+	return d
+}
+
+// Replace old with new in recorded frames, e.g. temp dirs
+func (d *driver) scrubbing(old, new string) *driver {
+	d.scrub = strings.NewReplacer(old, new)
+	return d
+}
+
+// Render frames with ANSI styles kept, escapes made visible
+func (d *driver) withColor() *driver {
+	d.color = true
+	return d
+}
+
+func (d *driver) send(msg tea.Msg) {
+	d.t.Helper()
+
+	if d.done {
+		d.t.Fatalf("message %v sent after the program quit", msg)
+	}
+
+	m, cmd := d.model.Update(msg)
+	d.model = m
+	d.exec(cmd)
+}
+
+// Run commands synchronously, feeding their messages back into the model
+func (d *driver) exec(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.QuitMsg:
+		d.done = true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			d.exec(c)
+		}
+	default:
+		d.send(msg)
+	}
+}
+
+// Press keys by name ("down", "enter", "ctrl+c") or as literal runes ("q")
+func (d *driver) press(keys ...string) *driver {
+	d.t.Helper()
+
+	for _, k := range keys {
+		d.send(keyMsg(k))
+	}
+	return d
+}
+
+// Record the current frame under a label
+func (d *driver) snapshot(label string) *driver {
+	view := d.model.View()
+	if d.color {
+		view = strings.ReplaceAll(view, "\x1b", `\e`)
+	} else {
+		view = ansi.Strip(view)
+	}
+	if d.scrub != nil {
+		view = d.scrub.Replace(view)
+	}
+
+	fmt.Fprintf(&d.frames, "── %s ──\n%s", label, view)
+	if !strings.HasSuffix(view, "\n") {
+		d.frames.WriteString("\n")
+	}
+	return d
+}
+
+func (d *driver) assertGolden(name string) {
+	d.t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	got := d.frames.String()
+
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			d.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			d.t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		d.t.Fatalf("missing golden file (run go test ./internal/ui -update): %v", err)
+	}
+	if got != string(want) {
+		d.t.Fatalf("frames differ from %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	switch k {
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	case "space":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	case "pgup":
+		return tea.KeyMsg{Type: tea.KeyPgUp}
+	case "pgdown":
+		return tea.KeyMsg{Type: tea.KeyPgDown}
+	case "home":
+		return tea.KeyMsg{Type: tea.KeyHome}
+	case "end":
+		return tea.KeyMsg{Type: tea.KeyEnd}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	case "ctrl+u":
+		return tea.KeyMsg{Type: tea.KeyCtrlU}
+	case "ctrl+n":
+		return tea.KeyMsg{Type: tea.KeyCtrlN}
+	case "ctrl+p":
+		return tea.KeyMsg{Type: tea.KeyCtrlP}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// Force 256-color styles regardless of the test's stderr
+func forceColor(m model) model {
+	renderer := lipgloss.NewRenderer(io.Discard)
+	renderer.SetColorProfile(termenv.ANSI256)
+
+	m.useColor = true
+	m.styles = newSelectorStyles(renderer, defaultTheme())
+	return m
+}
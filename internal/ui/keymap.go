@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// action is something a key can be bound to. The names are the ones used
+// under [selector.keys] in the config file.
+type action string
+
+const (
+	actUp       action = "up"
+	actDown     action = "down"
+	actPageUp   action = "page_up"
+	actPageDown action = "page_down"
+	actTop      action = "top"
+	actBottom   action = "bottom"
+	actLeft     action = "left"
+	actRight    action = "right"
+	actSelect   action = "select"
+	actQuit     action = "quit"
+	actMark     action = "mark"
+	actMarkAll  action = "mark_all"
+	actRename   action = "rename"
+	actEditPath action = "edit_path"
+	actTags     action = "tags"
+	actDelete   action = "delete"
+	actFilter   action = "filter"
+	actSort     action = "sort"
+	actReverse  action = "reverse"
+	actGroup    action = "group"
+	actTagOp    action = "tag_op"
+	actFocus    action = "focus"
+	actHelp     action = "help"
+)
+
+// Default bindings, in the order the help overlay lists them
+var defaultBindings = []struct {
+	action action
+	keys   []string
+	help   string
+}{
+	{actUp, []string{"up", "k"}, "move up"},
+	{actDown, []string{"down", "j"}, "move down"},
+	{actPageUp, []string{"pgup"}, "page up"},
+	{actPageDown, []string{"pgdown"}, "page down"},
+	{actTop, []string{"home", "g"}, "go to the first shortcut"},
+	{actBottom, []string{"end", "G"}, "go to the last shortcut"},
+	{actLeft, []string{"left", "h"}, "focus the tags"},
+	{actRight, []string{"right", "l"}, "focus the table"},
+	{actSelect, []string{"enter"}, "select"},
+	{actMark, []string{"space"}, "mark"},
+	{actMarkAll, []string{"a"}, "mark all"},
+	{actRename, []string{"r"}, "rename"},
+	{actEditPath, []string{"e"}, "edit path"},
+	{actTags, []string{"t"}, "edit tags"},
+	{actDelete, []string{"d"}, "delete"},
+	{actFilter, []string{"/"}, "filter"},
+	{actSort, []string{"s"}, "change the sort order"},
+	{actReverse, []string{"S"}, "reverse the sort"},
+	{actGroup, []string{"T"}, "group by tag"},
+	{actTagOp, []string{"o"}, "match any or all of the toggled tags"},
+	{actFocus, []string{"tab"}, "switch between tags and table"},
+	{actHelp, []string{"?"}, "show or hide this help"},
+	{actQuit, []string{"q", "esc", "ctrl+c"}, "quit"},
+}
+
+// Keymap maps keys to actions. The zero value is the default keymap.
+type Keymap struct {
+	actions  map[string]action
+	bindings map[action][]string
+}
+
+// Build a keymap from the defaults and per-action overrides. An override
+// replaces the default keys of its action and takes its keys away from
+// any other action.
+func NewKeymap(overrides map[string][]string) (Keymap, error) {
+	km := Keymap{
+		actions:  make(map[string]action),
+		bindings: make(map[action][]string),
+	}
+
+	for _, b := range defaultBindings {
+		if _, ok := overrides[string(b.action)]; ok {
+			continue
+		}
+		km.bindings[b.action] = normalizeKeys(b.keys)
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		keys := overrides[name]
+		a := action(name)
+		if !knownAction(a) {
+			return Keymap{}, fmt.Errorf("unknown action %q", name)
+		}
+		if len(keys) == 0 {
+			return Keymap{}, fmt.Errorf("action %q needs at least one key", name)
+		}
+		keys = normalizeKeys(keys)
+		for other, bound := range km.bindings {
+			km.bindings[other] = without(bound, keys)
+		}
+		km.bindings[a] = keys
+	}
+
+	for a, keys := range km.bindings {
+		for _, k := range keys {
+			km.actions[k] = a
+		}
+	}
+	return km, nil
+}
+
+func defaultKeymap() Keymap {
+	km, _ := NewKeymap(nil)
+	return km
+}
+
+func (km Keymap) orDefault() Keymap {
+	if km.actions == nil {
+		return defaultKeymap()
+	}
+	return km
+}
+
+// Action bound to a key, as reported by tea.KeyMsg.String(). ctrl+c
+// always quits unless it was bound to something else.
+func (km Keymap) lookup(key string) action {
+	if a, ok := km.actions[key]; ok {
+		return a
+	}
+	if key == "ctrl+c" {
+		return actQuit
+	}
+	return ""
+}
+
+// Keys bound to an action, for display
+func (km Keymap) keys(a action) []string {
+	keys := make([]string, len(km.bindings[a]))
+	for i, k := range km.bindings[a] {
+		keys[i] = displayKey(k)
+	}
+	return keys
+}
+
+// The first n keys of an action joined with "/", e.g. "up/k"
+func (km Keymap) describe(a action, n int) string {
+	keys := km.keys(a)
+	return strings.Join(keys[:min(n, len(keys))], "/")
+}
+
+// Join "key what" hints with " · ", skipping unbound actions
+func (km Keymap) hints(pairs ...string) string {
+	var parts []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if key := km.describe(action(pairs[i]), 1); key != "" {
+			parts = append(parts, key+" "+pairs[i+1])
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Help overlay for the given actions, one line per bound action. labels
+// replace the default descriptions where a view uses a key differently.
+func (km Keymap) helpView(actions []action, labels map[action]string) string {
+	var s strings.Builder
+	s.WriteString("Keys (any key to close):\n\n")
+
+	width := 0
+	for _, a := range actions {
+		width = max(width, len(strings.Join(km.keys(a), ", ")))
+	}
+	for _, a := range actions {
+		keys := strings.Join(km.keys(a), ", ")
+		if keys == "" {
+			continue
+		}
+		label, ok := labels[a]
+		if !ok {
+			label = actionHelp(a)
+		}
+		s.WriteString(fmt.Sprintf("  %s  %s\n", padRight(keys, width), label))
+	}
+	return s.String()
+}
+
+func actionHelp(a action) string {
+	for _, b := range defaultBindings {
+		if b.action == a {
+			return b.help
+		}
+	}
+	return string(a)
+}
+
+func knownAction(a action) bool {
+	for _, b := range defaultBindings {
+		if b.action == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys as tea.KeyMsg.String() reports them
+func normalizeKeys(keys []string) []string {
+	normalized := make([]string, len(keys))
+	for i, k := range keys {
+		if k == "space" {
+			k = " "
+		}
+		normalized[i] = k
+	}
+	return normalized
+}
+
+func displayKey(k string) string {
+	if k == " " {
+		return "space"
+	}
+	return k
+}
+
+func without(keys, drop []string) []string {
+	var kept []string
+	for _, k := range keys {
+		stolen := false
+		for _, d := range drop {
+			if k == d {
+				stolen = true
+				break
+			}
+		}
+		if !stolen {
+			kept = append(kept, k)
+		}
+	}
+	return kept
+}
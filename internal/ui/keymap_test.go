@@ -0,0 +1,119 @@
+//go:build !notui
+
+package ui
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikul1999-pixel/fs/pkg/config"
+	"github.com/muesli/termenv"
+)
+
+func TestKeymap_ActionsMatchConfig(t *testing.T) {
+	if len(config.KeyActions) != len(defaultBindings) {
+		t.Fatalf("config lists %d actions, the keymap has %d", len(config.KeyActions), len(defaultBindings))
+	}
+	for _, name := range config.KeyActions {
+		if !knownAction(action(name)) {
+			t.Fatalf("config action %q has no default binding", name)
+		}
+	}
+}
+
+func TestNewKeymap_OverridesReplaceAndSteal(t *testing.T) {
+	km, err := NewKeymap(map[string][]string{
+		"down": {"ctrl+n", "k"},
+		"mark": {"space", "x"},
+	})
+	if err != nil {
+		t.Fatalf("NewKeymap returned error: %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want action
+	}{
+		{"ctrl+n", actDown},
+		{"k", actDown},
+		{"j", ""}, // replaced
+		{"down", ""},
+		{"up", actUp},
+		{" ", actMark},
+		{"x", actMark},
+		{"ctrl+c", actQuit},
+	}
+	for _, tt := range tests {
+		if got := km.lookup(tt.key); got != tt.want {
+			t.Errorf("lookup(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	if got := km.keys(actUp); strings.Join(got, ",") != "up" {
+		t.Fatalf("expected k to be taken from up, got %v", got)
+	}
+
+	if _, err := NewKeymap(map[string][]string{"jump": {"J"}}); err == nil {
+		t.Fatal("expected an error for an unknown action")
+	}
+}
+
+func TestSelectorGolden_EmacsKeysAndHelp(t *testing.T) {
+	km, err := NewKeymap(map[string][]string{
+		"up":   {"up", "ctrl+p"},
+		"down": {"down", "ctrl+n"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDriver(t, InitialModel(goldenShortcuts(), SelectorOptions{NoColor: true, Keymap: km}))
+	d.snapshot("initial").
+		press("ctrl+n", "ctrl+n", "ctrl+p").snapshot("moved with ctrl+n and ctrl+p").
+		press("j").snapshot("j no longer bound").
+		press("?").snapshot("help").
+		press("q").snapshot("help closed")
+
+	d.assertGolden("emacs_keys_help")
+
+	if d.done {
+		t.Fatal("expected the key that closes the help not to quit")
+	}
+	if m := d.model.(model); m.cursor != 1 {
+		t.Fatalf("expected cursor on docs, got %d", m.cursor)
+	}
+}
+
+func TestManagerGolden_Help(t *testing.T) {
+	d := newManagerDriver(t, managerShortcuts(), ManagerOptions{NoColor: true})
+	d.press("?").snapshot("help")
+	d.assertGolden("manager_help")
+
+	d.press("esc")
+	if d.done || d.model.(manager).showHelp {
+		t.Fatal("expected esc to close the help without quitting")
+	}
+}
+
+func TestSelectorGolden_Themes(t *testing.T) {
+	renderer := lipgloss.NewRenderer(io.Discard)
+	renderer.SetColorProfile(termenv.ANSI256)
+
+	for _, name := range []string{"light", "high-contrast"} {
+		theme, err := config.SelectorConfig{Theme: name}.ResolveTheme()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts := SelectorOptions{Query: "src", FilterTags: []string{"proj"}}
+		shortcuts := goldenShortcuts()
+		shortcuts[1].Tags = []string{"proj"}
+		m := forceColor(InitialModel(shortcuts, opts))
+		m.styles = newSelectorStyles(renderer, theme)
+
+		d := newDriver(t, m).withColor()
+		d.snapshot(name)
+		d.assertGolden("theme_" + name)
+	}
+}
@@ -0,0 +1,764 @@
+//go:build !notui
+
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/pkg/config"
+)
+
+// Table columns, in the order s cycles through them
+type sortColumn int
+
+const (
+	sortByName sortColumn = iota
+	sortByPath
+	sortByTags
+	sortByCreated
+	sortByUpdated
+	sortByVisits
+	numSortColumns
+)
+
+var sortColumnTitles = [numSortColumns]string{"NAME", "PATH", "TAGS", "CREATED", "UPDATED", "VISITS"}
+
+type managerFocus int
+
+const (
+	focusTable managerFocus = iota
+	focusTags
+)
+
+type tagCount struct {
+	name  string
+	count int
+}
+
+// manager is the full-screen `fs ui` app: a tag sidebar, a sortable table
+// of shortcuts, a detail panel for the row under the cursor, and the same
+// input line as the selector for editing one row or all marked rows.
+type manager struct {
+	all  []storage.Shortcut
+	rows []int // indices into all that pass the filters, in table order
+
+	cursor   int
+	offset   int
+	sortBy   sortColumn
+	sortDesc bool
+
+	tags      []tagCount
+	tagCursor int // 0 is "all"
+	tagOffset int
+	focus     managerFocus
+
+	filtering bool
+	filter    []rune
+
+	marked   map[int]struct{} // shortcut IDs, stable across renames
+	selected *storage.Shortcut
+	quitting bool
+
+	width  int
+	height int
+
+	edit     editor
+	keymap   Keymap
+	showHelp bool
+	useColor bool
+	styles   managerStyles
+}
+
+type managerStyles struct {
+	title     lipgloss.Style
+	header    lipgloss.Style
+	cursor    lipgloss.Style
+	highlight lipgloss.Style
+	tag       lipgloss.Style
+	path      lipgloss.Style
+	dim       lipgloss.Style
+}
+
+// Size used until the terminal reports its own
+const (
+	defaultManagerWidth  = 100
+	defaultManagerHeight = 24
+	sidebarWidth         = 22
+)
+
+func newManager(shortcuts []storage.Shortcut, opts ManagerOptions) manager {
+	m := manager{
+		all:      shortcuts,
+		marked:   make(map[int]struct{}),
+		width:    defaultManagerWidth,
+		height:   defaultManagerHeight,
+		edit:     newEditor(opts.Store, opts.ExpandPath),
+		keymap:   opts.Keymap.orDefault(),
+		useColor: shouldUseColor(opts.NoColor),
+		styles:   newManagerStyles(lipgloss.NewRenderer(os.Stderr), themeOrDefault(opts.Theme)),
+	}
+	m.refresh()
+	return m
+}
+
+func newManagerStyles(renderer *lipgloss.Renderer, theme config.Theme) managerStyles {
+	return managerStyles{
+		title:     renderer.NewStyle().Bold(true),
+		header:    renderer.NewStyle().Bold(true).Underline(true),
+		cursor:    themeStyle(renderer, theme.Cursor),
+		highlight: themeStyle(renderer, theme.Highlight),
+		tag:       themeStyle(renderer, theme.MatchedTag),
+		path:      themeStyle(renderer, theme.Path),
+		dim:       themeStyle(renderer, theme.Status),
+	}
+}
+
+func (m manager) Init() tea.Cmd {
+	return nil
+}
+
+func (m manager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+
+	case tea.KeyMsg:
+		switch {
+		case m.showHelp:
+			// Any key closes the help, only quit goes further
+			m.showHelp = false
+			if msg.String() == "ctrl+c" {
+				m.quitting = true
+				return m, tea.Quit
+			}
+		case m.edit.active():
+			m.applyEdit(m.edit.update(msg))
+		case m.filtering:
+			m.updateFilter(msg)
+		case m.focus == focusTags:
+			return m.updateTags(msg)
+		default:
+			return m.updateTable(msg)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *manager) updateFilter(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.filtering = false
+		m.filter = nil
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyBackspace:
+		if len(m.filter) > 0 {
+			m.filter = m.filter[:len(m.filter)-1]
+		}
+	case tea.KeyCtrlU:
+		m.filter = nil
+	case tea.KeySpace:
+		m.filter = append(m.filter, ' ')
+	case tea.KeyRunes:
+		m.filter = append(m.filter, msg.Runes...)
+	}
+	m.refresh()
+}
+
+func (m manager) updateTags(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keymap.lookup(msg.String()) {
+	case actQuit:
+		// esc leaves the sidebar, other quit keys leave the app
+		if msg.String() == "esc" {
+			m.focus = focusTable
+			return m, nil
+		}
+		m.quitting = true
+		return m, tea.Quit
+	case actHelp:
+		m.showHelp = true
+		return m, nil
+	case actUp:
+		if m.tagCursor > 0 {
+			m.tagCursor--
+		}
+	case actDown:
+		if m.tagCursor < len(m.tags) {
+			m.tagCursor++
+		}
+	case actTop:
+		m.tagCursor = 0
+	case actBottom:
+		m.tagCursor = len(m.tags)
+	case actFocus, actSelect, actRight:
+		m.focus = focusTable
+		return m, nil
+	default:
+		return m, nil
+	}
+
+	m.cursor = 0
+	m.refresh()
+	return m, nil
+}
+
+func (m manager) updateTable(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.keymap.lookup(msg.String()) {
+	case actQuit:
+		// esc clears the filter first, quitting once there is nothing to clear
+		if msg.String() == "esc" && len(m.filter) > 0 {
+			m.filter = nil
+			m.refresh()
+			break
+		}
+		m.quitting = true
+		return m, tea.Quit
+
+	case actHelp:
+		m.showHelp = true
+
+	case actUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case actDown:
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+
+	case actPageUp:
+		m.cursor = max(m.cursor-m.tableHeight(), 0)
+
+	case actPageDown:
+		m.cursor = max(min(m.cursor+m.tableHeight(), len(m.rows)-1), 0)
+
+	case actTop:
+		m.cursor = 0
+
+	case actBottom:
+		m.cursor = max(len(m.rows)-1, 0)
+
+	case actFocus, actLeft:
+		m.focus = focusTags
+
+	case actFilter:
+		m.filtering = true
+
+	case actSort:
+		m.sortBy = (m.sortBy + 1) % numSortColumns
+		m.refresh()
+
+	case actReverse:
+		m.sortDesc = !m.sortDesc
+		m.refresh()
+
+	case actMark:
+		if len(m.rows) > 0 {
+			m.toggleMark(m.all[m.rows[m.cursor]].ID)
+			if m.cursor < len(m.rows)-1 {
+				m.cursor++
+			}
+		}
+
+	case actMarkAll:
+		m.toggleAll()
+
+	case actRename:
+		m.edit.start(modeRename, m.targets())
+
+	case actEditPath:
+		m.edit.start(modeEditPath, m.targets())
+
+	case actTags:
+		m.edit.start(modeTags, m.targets())
+
+	case actDelete:
+		m.edit.start(modeConfirmDelete, m.targets())
+
+	case actSelect:
+		if len(m.rows) == 0 {
+			break
+		}
+		sc := m.all[m.rows[m.cursor]]
+		m.selected = &sc
+		m.quitting = true
+		return m, tea.Quit
+	}
+
+	m.scroll()
+	return m, nil
+}
+
+func (m manager) toggleMark(id int) {
+	if _, ok := m.marked[id]; ok {
+		delete(m.marked, id)
+		return
+	}
+	m.marked[id] = struct{}{}
+}
+
+// Mark every row in the table, or clear all marks if they already are
+func (m manager) toggleAll() {
+	all := len(m.rows) > 0
+	for _, i := range m.rows {
+		if _, ok := m.marked[m.all[i].ID]; !ok {
+			all = false
+			break
+		}
+	}
+
+	if all {
+		clear(m.marked)
+		return
+	}
+	for _, i := range m.rows {
+		m.marked[m.all[i].ID] = struct{}{}
+	}
+}
+
+// Marked shortcuts in table order, or the row under the cursor if none are
+func (m manager) targets() []*storage.Shortcut {
+	var targets []*storage.Shortcut
+	for _, i := range m.rows {
+		if _, ok := m.marked[m.all[i].ID]; ok {
+			targets = append(targets, &m.all[i])
+		}
+	}
+	if len(targets) == 0 && len(m.rows) > 0 {
+		targets = append(targets, &m.all[m.rows[m.cursor]])
+	}
+	return targets
+}
+
+// Drop deleted shortcuts and rebuild tags and rows around the edit
+func (m *manager) applyEdit(result editResult) {
+	for _, name := range result.deleted {
+		for i, sc := range m.all {
+			if sc.Name == name {
+				delete(m.marked, sc.ID)
+				m.all = append(m.all[:i:i], m.all[i+1:]...)
+				break
+			}
+		}
+	}
+	m.refresh()
+}
+
+// Recount tags and recompute the rows from the tag, filter and sort
+func (m *manager) refresh() {
+	m.tags = countTags(m.all)
+	m.tagCursor = min(m.tagCursor, len(m.tags))
+	tag := m.activeTag()
+	tokens := strings.Fields(strings.ToLower(string(m.filter)))
+
+	m.rows = m.rows[:0]
+	for i, sc := range m.all {
+		if tag != "" && !hasTag(sc, tag) {
+			continue
+		}
+		if !matchesAll(sc, tokens) {
+			continue
+		}
+		m.rows = append(m.rows, i)
+	}
+
+	sort.SliceStable(m.rows, func(i, j int) bool {
+		a, b := &m.all[m.rows[i]], &m.all[m.rows[j]]
+		if m.sortDesc {
+			a, b = b, a
+		}
+		return lessBy(m.sortBy, a, b)
+	})
+
+	m.cursor = max(min(m.cursor, len(m.rows)-1), 0)
+	m.scroll()
+}
+
+// Tag selected in the sidebar, or "" for all shortcuts
+func (m manager) activeTag() string {
+	if m.tagCursor == 0 || m.tagCursor > len(m.tags) {
+		return ""
+	}
+	return m.tags[m.tagCursor-1].name
+}
+
+func countTags(shortcuts []storage.Shortcut) []tagCount {
+	counts := make(map[string]int)
+	for _, sc := range shortcuts {
+		for _, t := range sc.Tags {
+			counts[t]++
+		}
+	}
+
+	tags := make([]tagCount, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, tagCount{name: name, count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].name < tags[j].name })
+	return tags
+}
+
+func hasTag(sc storage.Shortcut, tag string) bool {
+	for _, t := range sc.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Every token appears in the name, path or a tag
+func matchesAll(sc storage.Shortcut, tokens []string) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	haystack := strings.ToLower(sc.Name + "\x00" + sc.Path + "\x00" + strings.Join(sc.Tags, "\x00"))
+	for _, token := range tokens {
+		if !strings.Contains(haystack, token) {
+			return false
+		}
+	}
+	return true
+}
+
+// Order by column, then by name so equal values stay predictable
+func lessBy(col sortColumn, a, b *storage.Shortcut) bool {
+	switch col {
+	case sortByPath:
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+	case sortByTags:
+		at, bt := strings.Join(a.Tags, ","), strings.Join(b.Tags, ",")
+		if at != bt {
+			return at < bt
+		}
+	case sortByCreated:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+	case sortByUpdated:
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		}
+	case sortByVisits:
+		if a.Visits != b.Visits {
+			return a.Visits < b.Visits
+		}
+	}
+	return a.Name < b.Name
+}
+
+// Rows of the table that fit on screen
+func (m manager) tableHeight() int {
+	// title, blank, header, blank, 4 detail lines, blank, 3 footer lines
+	return max(m.height-12, 3)
+}
+
+// Keep the cursors inside their visible windows
+func (m *manager) scroll() {
+	m.offset = scrollOffset(m.cursor, m.offset, m.tableHeight())
+	m.tagOffset = scrollOffset(m.tagCursor, m.tagOffset, m.tableHeight())
+}
+
+func scrollOffset(cursor, offset, size int) int {
+	if cursor < offset {
+		return cursor
+	}
+	if cursor >= offset+size {
+		return cursor - size + 1
+	}
+	return offset
+}
+
+func (m manager) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var s strings.Builder
+
+	if m.showHelp {
+		s.WriteString(m.renderTitle() + "\n\n")
+		s.WriteString(m.keymap.helpView(m.helpActions(), nil))
+		return m.fit(s.String())
+	}
+
+	s.WriteString(m.renderTitle() + "\n\n")
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, m.renderSidebar(), m.renderTable())
+	s.WriteString(body + "\n\n")
+	s.WriteString(m.renderDetail())
+	s.WriteString("\n")
+	s.WriteString(m.renderFooter())
+
+	return m.fit(s.String())
+}
+
+// Never wrap: a wrapped line would push the layout off screen
+func (m manager) fit(view string) string {
+	lines := strings.Split(strings.TrimSuffix(view, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, m.width, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Actions listed in the help overlay
+func (m manager) helpActions() []action {
+	actions := []action{
+		actUp, actDown, actPageUp, actPageDown, actTop, actBottom,
+		actFocus, actLeft, actRight, actFilter, actSort, actReverse,
+		actMark, actMarkAll,
+	}
+	if m.edit.store != nil {
+		actions = append(actions, actRename, actEditPath, actTags, actDelete)
+	}
+	return append(actions, actSelect, actHelp, actQuit)
+}
+
+func (m manager) renderTitle() string {
+	arrow := "▲"
+	if m.sortDesc {
+		arrow = "▼"
+	}
+
+	title := fmt.Sprintf("fs · %d of %d shortcuts · sorted by %s %s",
+		len(m.rows), len(m.all), strings.ToLower(sortColumnTitles[m.sortBy]), arrow)
+	if len(m.marked) > 0 {
+		title += fmt.Sprintf(" · %d marked", len(m.marked))
+	}
+	return m.applyStyle(title, m.styles.title)
+}
+
+func (m manager) renderSidebar() string {
+	var lines []string
+
+	header := "TAGS"
+	if m.focus == focusTags {
+		header = m.applyStyle(header, m.styles.cursor)
+	} else {
+		header = m.applyStyle(header, m.styles.header)
+	}
+	lines = append(lines, header)
+
+	entries := make([]tagCount, 0, len(m.tags)+1)
+	entries = append(entries, tagCount{name: "all", count: len(m.all)})
+	entries = append(entries, m.tags...)
+
+	end := min(m.tagOffset+m.tableHeight(), len(entries))
+	for i := m.tagOffset; i < end; i++ {
+		prefix := "  "
+		if i == m.tagCursor {
+			prefix = "> "
+		}
+		count := fmt.Sprint(entries[i].count)
+		name := ansi.Truncate(entries[i].name, sidebarWidth-len(prefix)-len(count)-3, "…")
+		line := prefix + padRight(name, sidebarWidth-len(prefix)-len(count)-2) + count
+		if i == m.tagCursor {
+			line = m.applyStyle(line, m.styles.cursor)
+		}
+		lines = append(lines, line)
+	}
+
+	style := lipgloss.NewStyle().Width(sidebarWidth).Height(m.tableHeight() + 1)
+	return style.Render(strings.Join(lines, "\n"))
+}
+
+// Widths of the fixed columns; the path takes what is left
+const (
+	nameColumnWidth   = 14
+	tagsColumnWidth   = 14
+	dateColumnWidth   = 10
+	visitsColumnWidth = 6
+	rowPrefixWidth    = 6 // "> [x] "
+)
+
+func (m manager) pathColumnWidth() int {
+	fixed := rowPrefixWidth + nameColumnWidth + tagsColumnWidth + 2*dateColumnWidth + visitsColumnWidth + 5
+	return max(m.width-sidebarWidth-fixed, 12)
+}
+
+func (m manager) renderTable() string {
+	widths := [numSortColumns]int{
+		nameColumnWidth, m.pathColumnWidth(), tagsColumnWidth,
+		dateColumnWidth, dateColumnWidth, visitsColumnWidth,
+	}
+
+	var lines []string
+
+	headers := make([]string, numSortColumns)
+	for col := sortColumn(0); col < numSortColumns; col++ {
+		title := sortColumnTitles[col]
+		if col == m.sortBy {
+			if m.sortDesc {
+				title += "▼"
+			} else {
+				title += "▲"
+			}
+		}
+		headers[col] = padRight(title, widths[col])
+	}
+	lines = append(lines, strings.Repeat(" ", rowPrefixWidth)+m.applyStyle(strings.Join(headers, " "), m.styles.header))
+
+	query := string(m.filter)
+	end := min(m.offset+m.tableHeight(), len(m.rows))
+	for r := m.offset; r < end; r++ {
+		sc := m.all[m.rows[r]]
+
+		prefix := "  "
+		if r == m.cursor && m.focus == focusTable {
+			prefix = m.applyStyle(">", m.styles.cursor) + " "
+		}
+		if _, ok := m.marked[sc.ID]; ok {
+			prefix += "[x] "
+		} else {
+			prefix += "[ ] "
+		}
+
+		cells := []string{
+			m.highlightCell(ansi.Truncate(sc.Name, widths[sortByName], "…"), query, widths[sortByName]),
+			m.highlightPath(middleTruncate(sc.Path, widths[sortByPath]), query, widths[sortByPath]),
+			m.applyStyle(padRight(ansi.Truncate(strings.Join(sc.Tags, ","), widths[sortByTags], "…"), widths[sortByTags]), m.styles.tag),
+			padRight(formatDate(sc.CreatedAt), widths[sortByCreated]),
+			padRight(formatDate(sc.UpdatedAt), widths[sortByUpdated]),
+			padLeft(fmt.Sprint(sc.Visits), widths[sortByVisits]),
+		}
+		lines = append(lines, prefix+strings.Join(cells, " "))
+	}
+
+	if len(m.rows) == 0 {
+		lines = append(lines, strings.Repeat(" ", rowPrefixWidth)+"No shortcuts match")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Highlight the filter within an already shortened cell and pad it
+func (m manager) highlightCell(cell, query string, width int) string {
+	return padRight(highlightByTokens(cell, query, m.useColor, m.styles.highlight), width)
+}
+
+func (m manager) highlightPath(cell, query string, width int) string {
+	if !m.useColor {
+		return padRight(cell, width)
+	}
+	return padRight(highlightWith(cell, query, &m.styles.path, m.styles.highlight), width)
+}
+
+func (m manager) renderDetail() string {
+	rule := strings.Repeat("─", max(m.width, 1))
+	if len(m.rows) == 0 {
+		return m.applyStyle(rule, m.styles.dim) + "\n\n\n\n"
+	}
+
+	sc := m.all[m.rows[m.cursor]]
+	tags := strings.Join(sc.Tags, ", ")
+	if tags == "" {
+		tags = "-"
+	}
+
+	var s strings.Builder
+	s.WriteString(m.applyStyle(rule, m.styles.dim) + "\n")
+	s.WriteString(fmt.Sprintf("%s  %s\n", m.applyStyle(sc.Name, m.styles.title), sc.Path))
+	s.WriteString(fmt.Sprintf("tags: %s\n", tags))
+	s.WriteString(fmt.Sprintf("created %s · updated %s · %s\n",
+		formatTime(sc.CreatedAt), formatTime(sc.UpdatedAt), formatVisits(sc)))
+	return s.String()
+}
+
+func (m manager) renderFooter() string {
+	var s strings.Builder
+
+	switch {
+	case m.edit.active():
+		s.WriteString(m.edit.render())
+	case m.filtering:
+		s.WriteString(fmt.Sprintf("Filter: %s_\n", string(m.filter)))
+	case len(m.filter) > 0:
+		s.WriteString(fmt.Sprintf("Filter: %s (esc to clear)\n", string(m.filter)))
+	}
+	if m.edit.status != "" {
+		s.WriteString(m.applyStyle(m.edit.status, m.styles.dim) + "\n")
+	}
+
+	hints := []string{
+		string(actFilter), "filter",
+		string(actSort), "sort",
+		string(actReverse), "reverse",
+		string(actFocus), "tags",
+		string(actMark), "mark",
+		string(actMarkAll), "all",
+	}
+	if m.edit.store != nil {
+		hints = append(hints,
+			string(actRename), "rename",
+			string(actEditPath), "path",
+			string(actTags), "tags",
+			string(actDelete), "delete")
+	}
+	hints = append(hints, string(actSelect), "jump", string(actHelp), "help", string(actQuit), "quit")
+	s.WriteString(m.applyStyle(m.keymap.hints(hints...), m.styles.dim) + "\n")
+	return s.String()
+}
+
+func (m manager) applyStyle(text string, style lipgloss.Style) string {
+	if !m.useColor {
+		return text
+	}
+	return style.Render(text)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func formatVisits(sc storage.Shortcut) string {
+	switch sc.Visits {
+	case 0:
+		return "never visited"
+	case 1:
+		return "1 visit, last " + formatTime(sc.LastVisitedAt)
+	}
+	return fmt.Sprintf("%d visits, last %s", sc.Visits, formatTime(sc.LastVisitedAt))
+}
+
+// Run the full-screen manager. The shortcut picked with enter is returned,
+// or nil if the user quit.
+func RunManager(shortcuts []storage.Shortcut, opts ManagerOptions) (*storage.Shortcut, error) {
+	// stderr, so the picked path can be captured from stdout
+	return runManager(shortcuts, opts, os.Stdin, os.Stderr, tea.WithAltScreen())
+}
+
+func runManager(shortcuts []storage.Shortcut, opts ManagerOptions, in io.Reader, out io.Writer, extra ...tea.ProgramOption) (*storage.Shortcut, error) {
+	programOpts := append([]tea.ProgramOption{tea.WithInput(in), tea.WithOutput(out)}, extra...)
+	p := tea.NewProgram(newManager(shortcuts, opts), programOpts...)
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	return finalModel.(manager).selected, nil
+}
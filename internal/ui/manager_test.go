@@ -0,0 +1,136 @@
+//go:build !notui
+
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mikul1999-pixel/fs/internal/storage"
+)
+
+func managerShortcuts() []storage.Shortcut {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	return []storage.Shortcut{
+		{ID: 1, Name: "api", Path: "/home/dev/src/api", Tags: []string{"go", "proj"},
+			CreatedAt: day(1), UpdatedAt: day(9), Visits: 12, LastVisitedAt: day(20)},
+		{ID: 2, Name: "docs", Path: "/home/dev/notes/docs",
+			CreatedAt: day(2), UpdatedAt: day(2)},
+		{ID: 3, Name: "web", Path: "/home/dev/src/web", Tags: []string{"frontend", "proj"},
+			CreatedAt: day(3), UpdatedAt: day(4), Visits: 3, LastVisitedAt: day(5)},
+		{ID: 4, Name: "infra", Path: "/home/dev/work/infrastructure/terraform/modules", Tags: []string{"ops"},
+			CreatedAt: day(4), UpdatedAt: day(4), Visits: 1, LastVisitedAt: day(4)},
+	}
+}
+
+// Dates render in local time; pin it so frames match everywhere
+func pinLocalTime(t *testing.T) {
+	t.Helper()
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+}
+
+func newManagerDriver(t *testing.T, shortcuts []storage.Shortcut, opts ManagerOptions) *driver {
+	t.Helper()
+	pinLocalTime(t)
+
+	d := newDriver(t, newManager(shortcuts, opts))
+	d.send(tea.WindowSizeMsg{Width: 110, Height: 20})
+	return d
+}
+
+func TestManagerGolden_SortFilterAndTags(t *testing.T) {
+	d := newManagerDriver(t, managerShortcuts(), ManagerOptions{NoColor: true})
+
+	d.snapshot("initial").
+		press("s", "s", "s", "s", "s").snapshot("sorted by visits").
+		press("S").snapshot("most visited first").
+		press("tab", "down", "down", "down", "down").snapshot("proj tag").
+		press("enter", "down").snapshot("back in table").
+		press("tab", "up", "up", "up", "up", "tab", "/", "src").snapshot("filtering").
+		press("enter").snapshot("filter kept").
+		press("esc").snapshot("filter cleared")
+
+	d.assertGolden("manager_sort_filter")
+}
+
+func TestManagerGolden_NarrowAndEmpty(t *testing.T) {
+	d := newManagerDriver(t, managerShortcuts(), ManagerOptions{NoColor: true})
+
+	d.send(tea.WindowSizeMsg{Width: 60, Height: 14})
+	d.snapshot("narrow").
+		press("/", "nothing").snapshot("no matches")
+
+	d.assertGolden("manager_narrow")
+}
+
+func TestManagerUpdate_EnterSelects(t *testing.T) {
+	d := newManagerDriver(t, managerShortcuts(), ManagerOptions{NoColor: true})
+	d.press("s", "s", "s", "s", "s", "S", "enter")
+
+	m := d.model.(manager)
+	if !d.done || m.selected == nil || m.selected.Name != "api" {
+		t.Fatalf("expected the most visited shortcut to be picked, got done=%v %+v", d.done, m.selected)
+	}
+}
+
+func TestManagerUpdate_BulkTagAndDelete(t *testing.T) {
+	_, s, opts := newActionFixture(t)
+	shortcuts, err := s.ListShortcuts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newManagerDriver(t, shortcuts, ManagerOptions{NoColor: true, Store: s, ExpandPath: opts.ExpandPath})
+
+	d.press("a", "t", "+infra -proj", "enter")
+	m := d.model.(manager)
+	if !strings.HasPrefix(m.edit.status, "Updated 2 shortcuts") {
+		t.Fatalf("expected a bulk tag status, got %q", m.edit.status)
+	}
+	for _, name := range []string{"api", "web"} {
+		tags, err := s.GetShortcutTags(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hasTag(storage.Shortcut{Tags: tags}, "infra") || hasTag(storage.Shortcut{Tags: tags}, "proj") {
+			t.Fatalf("expected %s to gain infra and lose proj, got %v", name, tags)
+		}
+	}
+	if len(m.tags) != 2 || m.tags[1].name != "infra" || m.tags[1].count != 2 {
+		t.Fatalf("expected the sidebar to recount tags, got %+v", m.tags)
+	}
+
+	d.press("r")
+	if m := d.model.(manager); m.edit.active() {
+		t.Fatal("expected rename to refuse several marked shortcuts")
+	}
+
+	d.press("d", "y")
+	m = d.model.(manager)
+	if len(m.all) != 0 || len(m.marked) != 0 {
+		t.Fatalf("expected both shortcuts deleted and unmarked, got %d left, %d marked", len(m.all), len(m.marked))
+	}
+	remaining, err := s.ListShortcuts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 0 {
+		t.Fatalf("expected the store to be empty, got %v", remaining)
+	}
+}
+
+func TestRunManager_QuitReturnsNil(t *testing.T) {
+	var out bytes.Buffer
+	selected, err := runManager(managerShortcuts(), ManagerOptions{NoColor: true}, strings.NewReader("q"), &out)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if selected != nil {
+		t.Fatalf("expected no selection after q, got %+v", selected)
+	}
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/pkg/config"
+)
+
+// The options live apart from the selector and manager, so a build without
+// the TUI (-tags notui) can still describe what to run
+
+type SelectorOptions struct {
+	Query      string
+	FilterTags []string
+	NoColor    bool
+	// Mark several shortcuts with space before confirming
+	Multi bool
+	// Enables rename, edit path, tag and delete from the list
+	Store storage.Storage
+	// Resolves ~ and relative paths typed while editing a path
+	ExpandPath func(string) (string, error)
+	// Colors, nil for the default theme
+	Theme *config.Theme
+	// Key bindings, the zero value for the defaults
+	Keymap Keymap
+	// Initial order, changed with s
+	Sort SortMode
+	// Show shortcuts under a header per tag, toggled with T
+	Group bool
+	// Clock for recency in match scores and double clicks, time.Now if nil
+	Now func() time.Time
+	// How FilterTags combine when tags are toggled: "or" (default) or "and"
+	TagOp string
+	// Take the whole screen and handle the mouse: the wheel scrolls, a click
+	// moves the cursor, a double click selects and clicking a tag filters on it
+	Mouse bool
+}
+
+type ManagerOptions struct {
+	NoColor bool
+	// Enables rename, edit path, tag and delete, one at a time or in bulk
+	Store storage.Storage
+	// Resolves ~ and relative paths typed while editing a path
+	ExpandPath func(string) (string, error)
+	// Colors, nil for the default theme
+	Theme *config.Theme
+	// Key bindings, the zero value for the defaults
+	Keymap Keymap
+}
@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/mikul1999-pixel/fs/internal/storage"
+)
+
+// ErrNoChoice is returned by Prompt when the input ends before a choice
+var ErrNoChoice = errors.New("no choice made")
+
+// Prompt lists the shortcuts numbered, in the selector's order, and reads a
+// choice from a line of in: a number or the start of a name, several of
+// them with opts.Multi. It draws nothing but lines of plain text, for
+// screen readers and dumb terminals, and needs no terminal at all, so
+// scripts can pipe a choice in.
+func Prompt(shortcuts []storage.Shortcut, opts SelectorOptions, in io.Reader, out io.Writer) ([]storage.Shortcut, error) {
+	f, ok := in.(*os.File)
+	typed := ok && isatty.IsTerminal(f.Fd())
+	return prompt(shortcuts, opts, in, out, typed)
+}
+
+// With typed, someone is answering at a terminal: mistakes ask again and a
+// name matching several shortcuts lists just those
+func prompt(shortcuts []storage.Shortcut, opts SelectorOptions, in io.Reader, out io.Writer, typed bool) ([]storage.Shortcut, error) {
+	if len(shortcuts) == 0 {
+		return nil, fmt.Errorf("no shortcuts to select from")
+	}
+
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	listed := Sorted(shortcuts, opts.Sort, opts.Query, now())
+	reader := bufio.NewReader(in)
+
+	for {
+		for i, sc := range listed {
+			line := fmt.Sprintf("%d. %s -> %s", i+1, sc.Name, sc.Path)
+			if len(sc.Tags) > 0 {
+				line += " [" + strings.Join(sc.Tags, ", ") + "]"
+			}
+			fmt.Fprintln(out, line)
+		}
+		if opts.Multi {
+			fmt.Fprintf(out, "Pick shortcuts (1-%d or names, separated by spaces): ", len(listed))
+		} else {
+			fmt.Fprintf(out, "Pick a shortcut (1-%d or the start of a name): ", len(listed))
+		}
+
+		line, err := reader.ReadString('\n')
+		if !typed {
+			// Piped input is not echoed, end the prompt's line
+			fmt.Fprintln(out)
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read choice: %w", err)
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n' })
+		if len(fields) == 0 {
+			return nil, ErrNoChoice
+		}
+
+		chosen, several, err := choose(listed, fields, opts.Multi)
+		switch {
+		case err == nil:
+			return chosen, nil
+		case !typed:
+			return nil, err
+		case len(several) > 0:
+			fmt.Fprintf(out, "'%s' matches %d shortcuts:\n", fields[0], len(several))
+			listed = several
+		default:
+			fmt.Fprintln(out, err)
+		}
+	}
+}
+
+// The shortcuts fields pick out of listed. A single choice matching several
+// shortcuts returns them as several, along with the error.
+func choose(listed []storage.Shortcut, fields []string, multi bool) (chosen, several []storage.Shortcut, err error) {
+	if len(fields) > 1 && !multi {
+		return nil, nil, fmt.Errorf("invalid choice '%s': expected a single number or name", strings.Join(fields, " "))
+	}
+
+	seen := make(map[int]bool)
+	for _, field := range fields {
+		matches := matchChoice(listed, field)
+		switch {
+		case len(matches) == 0:
+			return nil, nil, fmt.Errorf("invalid choice '%s': expected a number from 1 to %d or the start of a name", field, len(listed))
+		case len(matches) > 1:
+			for _, i := range matches {
+				several = append(several, listed[i])
+			}
+			return nil, several, fmt.Errorf("invalid choice '%s': matches %d shortcuts", field, len(matches))
+		}
+		if i := matches[0]; !seen[i] {
+			seen[i] = true
+			chosen = append(chosen, listed[i])
+		}
+	}
+	return chosen, nil, nil
+}
+
+// Positions in listed a choice stands for: its number, the shortcut with
+// that name, or those whose names start with it, ignoring case
+func matchChoice(listed []storage.Shortcut, field string) []int {
+	if n, err := strconv.Atoi(field); err == nil {
+		if n < 1 || n > len(listed) {
+			return nil
+		}
+		return []int{n - 1}
+	}
+
+	var prefixed []int
+	for i, sc := range listed {
+		if strings.EqualFold(sc.Name, field) {
+			return []int{i}
+		}
+		if strings.HasPrefix(strings.ToLower(sc.Name), strings.ToLower(field)) {
+			prefixed = append(prefixed, i)
+		}
+	}
+	return prefixed
+}
@@ -0,0 +1,69 @@
+//go:build !notui
+
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+)
+
+func TestPrompt_ReadsNumbersInSelectorOrder(t *testing.T) {
+	var out bytes.Buffer
+	chosen, err := Prompt(managerShortcuts(), SelectorOptions{Sort: SortByPath}, strings.NewReader("2\n"), &out)
+	if err != nil {
+		t.Fatalf("Prompt returned error: %v", err)
+	}
+	// by path: docs, api, web, infra
+	if len(chosen) != 1 || chosen[0].Name != "api" {
+		t.Fatalf("expected api, got %+v", chosen)
+	}
+	if !strings.HasPrefix(out.String(), "1. docs -> /home/dev/notes/docs\n2. api -> /home/dev/src/api [go, proj]\n") {
+		t.Fatalf("unexpected list:\n%s", out.String())
+	}
+
+	chosen, err = Prompt(managerShortcuts(), SelectorOptions{Multi: true}, strings.NewReader("4,1 WE"), &out)
+	if err != nil || len(chosen) != 2 || chosen[0].Name != "web" || chosen[1].Name != "api" {
+		t.Fatalf("expected web and api once each, got %+v, %v", chosen, err)
+	}
+
+	if _, err := Prompt(managerShortcuts(), SelectorOptions{}, strings.NewReader(""), &out); !errors.Is(err, ErrNoChoice) {
+		t.Fatalf("expected ErrNoChoice at the end of the input, got %v", err)
+	}
+	if _, err := Prompt(managerShortcuts(), SelectorOptions{}, strings.NewReader("9\n"), &out); err == nil {
+		t.Fatal("expected an error for a number past the list")
+	}
+}
+
+func TestPrompt_TypedNamesNarrowAndRetry(t *testing.T) {
+	shortcuts := append(managerShortcuts(), storage.Shortcut{Name: "apidocs", Path: "/home/dev/src/api/docs"})
+
+	// Piped in, a name matching several is a mistake
+	var out bytes.Buffer
+	if _, err := prompt(shortcuts, SelectorOptions{}, strings.NewReader("ap\n"), &out, false); err == nil {
+		t.Fatal("expected an error for a name matching several shortcuts")
+	}
+
+	// Typed, it lists those and asks again, as do mistakes
+	out.Reset()
+	chosen, err := prompt(shortcuts, SelectorOptions{}, strings.NewReader("ap\nzz\n2\n"), &out, true)
+	if err != nil || len(chosen) != 1 || chosen[0].Name != "apidocs" {
+		t.Fatalf("expected apidocs, got %+v, %v", chosen, err)
+	}
+	want := "'ap' matches 2 shortcuts:\n" +
+		"1. api -> /home/dev/src/api [go, proj]\n" +
+		"2. apidocs -> /home/dev/src/api/docs\n" +
+		"Pick a shortcut (1-2 or the start of a name): invalid choice 'zz': expected a number from 1 to 2 or the start of a name\n"
+	if !strings.Contains(out.String(), want) {
+		t.Fatalf("expected the narrowed list and the mistake, got:\n%s", out.String())
+	}
+
+	// An exact name wins over longer names starting with it
+	chosen, err = prompt(shortcuts, SelectorOptions{}, strings.NewReader("API\n"), &out, false)
+	if err != nil || chosen[0].Name != "api" {
+		t.Fatalf("expected api, got %+v, %v", chosen, err)
+	}
+}
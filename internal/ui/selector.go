@@ -1,32 +1,57 @@
+//go:build !notui
+
 package ui
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/mattn/go-isatty"
 	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/pkg/config"
+	"github.com/rivo/uniseg"
 )
 
-type SelectorOptions struct {
-	Query      string
-	FilterTags []string
-	NoColor    bool
-}
-
 type model struct {
 	shortcuts []storage.Shortcut
-	cursor    int
+	lines     []listLine // shortcuts in display order, with tag headers when grouped
+	cursor    int        // always on a shortcut line
+	offset    int        // first line on screen
+	width     int        // terminal size, 0 until reported
+	height    int
 	selected  *storage.Shortcut
 	quitting  bool
+	multi     bool
+	marked    map[string]struct{}
+	chosen    []storage.Shortcut
+
+	edit      editor
+	keymap    Keymap
+	showHelp  bool
+	sortMode  SortMode
+	grouped   bool
+	now       func() time.Time
 	query     string
 	tagFilter map[string]struct{}
+	tagOp     string
+	tagFocus  bool // keys go to the tag bar
+	tagCursor int
+	browse    *browser // set while drilling into a shortcut's folders
+	jump      string   // digits typed so far, the row number to go to
 	useColor  bool
 	styles    selectorStyles
+
+	lastClick   int // line of the last click, -1 for none
+	lastClickAt time.Time
 }
 
 type selectorStyles struct {
@@ -34,6 +59,16 @@ type selectorStyles struct {
 	highlight  lipgloss.Style
 	tag        lipgloss.Style
 	matchedTag lipgloss.Style
+	path       lipgloss.Style
+	more       lipgloss.Style
+}
+
+// A row of the list: a shortcut, or a tag header when grouped
+type listLine struct {
+	item   int // index into shortcuts, -1 for headers
+	number int // position among the shortcut lines, shown as "N."
+	header string
+	count  int // shortcuts under the header
 }
 
 func InitialModel(shortcuts []storage.Shortcut, opts SelectorOptions) model {
@@ -45,14 +80,33 @@ func InitialModel(shortcuts []storage.Shortcut, opts SelectorOptions) model {
 	useColor := shouldUseColor(opts.NoColor)
 	renderer := lipgloss.NewRenderer(os.Stderr)
 
-	return model{
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	tagOp, err := config.NormalizeTagOp(opts.TagOp)
+	if err != nil {
+		tagOp = "or"
+	}
+
+	m := model{
 		shortcuts: shortcuts,
 		cursor:    0,
+		sortMode:  opts.Sort,
+		grouped:   opts.Group,
+		now:       now,
 		query:     opts.Query,
 		tagFilter: tagFilter,
+		tagOp:     tagOp,
+		multi:     opts.Multi,
+		marked:    make(map[string]struct{}),
+		edit:      newEditor(opts.Store, opts.ExpandPath),
+		keymap:    opts.Keymap.orDefault(),
 		useColor:  useColor,
-		styles:    newSelectorStyles(renderer),
+		styles:    newSelectorStyles(renderer, themeOrDefault(opts.Theme)),
 	}
+	m.layout("")
+	return m
 }
 
 func (m model) Init() tea.Cmd {
@@ -61,32 +115,548 @@ func (m model) Init() tea.Cmd {
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+		if m.browse != nil {
+			m.scrollBrowse()
+		}
+
+	case tea.MouseMsg:
+		if m.edit.active() || m.showHelp || m.browse != nil {
+			return m, nil
+		}
+		return m.updateMouse(msg)
+
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q", "esc":
+		if m.edit.active() {
+			m.applyEdit(m.edit.update(msg))
+			m.scroll()
+			return m, nil
+		}
+
+		act := m.keymap.lookup(msg.String())
+		if m.showHelp {
+			// Any key closes the help, only quit goes further
+			m.showHelp = false
+			if msg.String() != "ctrl+c" {
+				return m, nil
+			}
+		}
+		if m.browse != nil {
+			return m.updateBrowse(msg)
+		}
+		if m.tagFocus && act != actQuit {
+			return m.updateTagBar(msg)
+		}
+		if m.tagFocus && msg.String() == "esc" {
+			m.tagFocus = false
+			return m, nil
+		}
+
+		if act == "" && isDigit(msg) {
+			m.jumpTo(msg.Runes[0])
+			m.scroll()
+			return m, nil
+		}
+		m.jump = ""
+
+		switch act {
+		case actQuit:
 			m.quitting = true
 			return m, tea.Quit
 
-		case "up", "k":
-			if m.cursor > 0 {
-				m.cursor--
+		case actHelp:
+			m.showHelp = true
+
+		case actUp:
+			m.moveTo(m.cursor-1, -1)
+
+		case actDown:
+			m.moveTo(m.cursor+1, 1)
+
+		case actPageUp:
+			m.moveTo(max(m.cursor-m.listHeight(), 0), -1)
+
+		case actPageDown:
+			m.moveTo(min(m.cursor+m.listHeight(), len(m.lines)-1), 1)
+
+		case actTop:
+			m.moveTo(0, 1)
+
+		case actBottom:
+			m.moveTo(len(m.lines)-1, -1)
+
+		case actSort:
+			m.sortMode = (m.sortMode + 1) % numSortModes
+			m.layout(m.currentName())
+
+		case actGroup:
+			m.grouped = !m.grouped
+			m.layout(m.currentName())
+
+		case actFocus:
+			m.tagFocus = m.showsTagBar()
+
+		case actTagOp:
+			m.switchTagOp()
+
+		case actRight:
+			m.openBrowser()
+			if m.browse != nil {
+				m.scrollBrowse()
+				return m, nil
+			}
+
+		case actMark:
+			sc := m.current()
+			if sc == nil {
+				break
+			}
+			if m.multi {
+				m.toggleMark(sc.Name)
+				break
 			}
+			m.selected = sc
+			m.quitting = true
+			return m, tea.Quit
 
-		case "down", "j":
-			if m.cursor < len(m.shortcuts)-1 {
-				m.cursor++
+		case actMarkAll:
+			if m.multi {
+				m.toggleAll()
 			}
 
-		case "enter", " ":
-			m.selected = &m.shortcuts[m.cursor]
+		case actRename:
+			m.startEdit(modeRename)
+
+		case actEditPath:
+			m.startEdit(modeEditPath)
+
+		case actTags:
+			m.startEdit(modeTags)
+
+		case actDelete:
+			m.startEdit(modeConfirmDelete)
+
+		case actSelect:
+			sc := m.current()
+			if sc == nil {
+				break
+			}
+			m.selected = sc
+			if m.multi {
+				m.chosen = m.markedShortcuts()
+			}
 			m.quitting = true
 			return m, tea.Quit
 		}
+		m.scroll()
+	}
+
+	return m, nil
+}
+
+func isDigit(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyRunes && len(msg.Runes) == 1 && msg.Runes[0] >= '0' && msg.Runes[0] <= '9'
+}
+
+// Move to the row numbered by the digits typed so far. A digit that makes
+// no row's number starts a new one.
+func (m *model) jumpTo(digit rune) {
+	for _, jump := range []string{m.jump + string(digit), string(digit)} {
+		for i, line := range m.lines {
+			if line.item >= 0 && strconv.Itoa(line.number) == jump {
+				m.jump = jump
+				m.cursor = i
+				return
+			}
+		}
+	}
+	m.jump = ""
+}
+
+// Clicks are told apart from double clicks by this
+const doubleClickTime = 400 * time.Millisecond
+
+// Wheel scrolling, clicks on rows and double clicks to select. Clicking a
+// tag toggles it in the tag filter.
+func (m model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.moveTo(m.cursor-1, -1)
+		m.scroll()
+		return m, nil
+	case tea.MouseButtonWheelDown:
+		m.moveTo(m.cursor+1, 1)
+		m.scroll()
+		return m, nil
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+	default:
+		return m, nil
 	}
 
+	if m.showsTagBar() && msg.Y == m.tagBarRow() {
+		_, chips := m.renderTagBar()
+		for _, chip := range chips {
+			if msg.X >= chip.start && msg.X < chip.end {
+				m.toggleTag(chip.tag)
+				break
+			}
+		}
+		return m, nil
+	}
+
+	i, ok := m.lineAt(msg.Y)
+	if !ok || m.lines[i].item < 0 {
+		return m, nil
+	}
+
+	_, chips := m.renderRow(i)
+	for _, chip := range chips {
+		if msg.X >= chip.start && msg.X < chip.end {
+			m.toggleTag(chip.tag)
+			return m, nil
+		}
+	}
+
+	now := m.now()
+	double := i == m.lastClick && now.Sub(m.lastClickAt) < doubleClickTime
+	m.cursor, m.lastClick, m.lastClickAt = i, i, now
+	if double && !m.multi {
+		m.selected = m.current()
+		m.quitting = true
+		return m, tea.Quit
+	}
+	if double {
+		m.toggleMark(m.current().Name)
+		m.lastClick = -1
+	}
+	m.scroll()
 	return m, nil
 }
 
+// Lines above the list: the title, the order and tag bar when shown, and
+// a blank line
+func (m model) topLines() int {
+	top := 2
+	if m.showsOrder() {
+		top++
+	}
+	if m.showsTagBar() {
+		top++
+	}
+	return top
+}
+
+// Screen row of the tag bar, right above the blank line
+func (m model) tagBarRow() int {
+	return m.topLines() - 2
+}
+
+// Line of the list shown at screen row y, the way View lays it out
+func (m model) lineAt(y int) (int, bool) {
+	top := m.topLines()
+	if countShortcuts(m.lines[:m.offset]) > 0 {
+		top++ // "↑ N more"
+	}
+
+	i := m.offset + y - top
+	if y < top || i >= min(m.offset+m.listHeight(), len(m.lines)) {
+		return 0, false
+	}
+	return i, true
+}
+
+// Add a tag to the filter or take it out, then re-filter the list
+func (m *model) toggleTag(tag string) {
+	tag = strings.ToLower(tag)
+	if _, ok := m.tagFilter[tag]; ok {
+		delete(m.tagFilter, tag)
+	} else {
+		m.tagFilter[tag] = struct{}{}
+	}
+	m.layout(m.currentName())
+}
+
+func (m model) tagActive(tag string) bool {
+	_, ok := m.tagFilter[strings.ToLower(tag)]
+	return ok
+}
+
+// Whether a shortcut has any (or, with the and operator, all) filter tags
+func (m model) passesTags(sc storage.Shortcut) bool {
+	if len(m.tagFilter) == 0 {
+		return true
+	}
+
+	matched := 0
+	for _, tag := range sc.Tags {
+		if m.tagActive(tag) {
+			matched++
+		}
+	}
+	if m.tagOp == "and" {
+		return matched == len(m.tagFilter)
+	}
+	return matched > 0
+}
+
+// Shortcuts that fit on screen, or all of them until the terminal has
+// reported its size
+func (m model) listHeight() int {
+	if m.height <= 0 {
+		return max(len(m.lines), 1)
+	}
+
+	// lines above the list and both scroll indicators
+	reserved := m.topLines() + 2
+	if m.multi {
+		reserved += 2
+	}
+	if m.edit.store != nil {
+		// blank line, edit or hint line, completions, status
+		reserved += 4
+	}
+	return max(m.height-reserved, 1)
+}
+
+// Keep the cursor inside the window of shortcuts on screen
+func (m *model) scroll() {
+	size := m.listHeight()
+	m.offset = scrollOffset(m.cursor, m.offset, size)
+	// Show the header above the first shortcut of a group
+	if m.offset > 0 && m.offset == m.cursor && m.lines[m.offset-1].item < 0 {
+		m.offset--
+	}
+	m.offset = max(min(m.offset, len(m.lines)-size), 0)
+}
+
+// Rebuild the lines from the sort mode and grouping, keeping the cursor on
+// the shortcut named keep when it's still listed
+func (m *model) layout(keep string) {
+	order := make([]int, 0, len(m.shortcuts))
+	for i, sc := range m.shortcuts {
+		if m.passesTags(sc) {
+			order = append(order, i)
+		}
+	}
+	sortShortcuts(m.shortcuts, order, m.sortMode, m.query, m.now())
+
+	m.lines = m.lines[:0]
+	if m.grouped {
+		m.lines = append(m.lines, m.groupByTag(order)...)
+	} else {
+		for _, i := range order {
+			m.lines = append(m.lines, listLine{item: i})
+		}
+	}
+
+	number := 0
+	for i := range m.lines {
+		if m.lines[i].item >= 0 {
+			number++
+			m.lines[i].number = number
+		}
+	}
+
+	m.lastClick = -1
+	cursor := min(m.cursor, len(m.lines)-1)
+	for i, line := range m.lines {
+		if line.item >= 0 && m.shortcuts[line.item].Name == keep {
+			cursor = i
+			break
+		}
+	}
+	m.cursor = max(cursor, 0)
+	m.moveTo(m.cursor, 1)
+	m.scroll()
+}
+
+// Bucket shortcuts under each of their tags, leaving out the tags being
+// filtered on since every shortcut has them. A shortcut with several tags
+// is listed under each.
+func (m model) groupByTag(order []int) []listLine {
+	groups := make(map[string][]int)
+	var untagged []int
+	for _, i := range order {
+		grouped := false
+		for _, tag := range m.shortcuts[i].Tags {
+			if _, ok := m.tagFilter[strings.ToLower(tag)]; ok {
+				continue
+			}
+			groups[tag] = append(groups[tag], i)
+			grouped = true
+		}
+		if !grouped {
+			untagged = append(untagged, i)
+		}
+	}
+
+	tags := make([]string, 0, len(groups))
+	for tag := range groups {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	var lines []listLine
+	add := func(header string, items []int) {
+		lines = append(lines, listLine{item: -1, header: header, count: len(items)})
+		for _, i := range items {
+			lines = append(lines, listLine{item: i})
+		}
+	}
+	for _, tag := range tags {
+		add(tag, groups[tag])
+	}
+	if len(untagged) > 0 {
+		header := "untagged"
+		if len(m.tagFilter) > 0 {
+			header = "no other tags"
+		}
+		add(header, untagged)
+	}
+	return lines
+}
+
+// Move the cursor to line i, or the nearest shortcut from there in
+// direction dir, or the nearest one the other way
+func (m *model) moveTo(i, dir int) {
+	for _, d := range []int{dir, -dir} {
+		for j := i; j >= 0 && j < len(m.lines); j += d {
+			if m.lines[j].item >= 0 {
+				m.cursor = j
+				return
+			}
+		}
+	}
+}
+
+// Shortcut under the cursor, nil when the list is empty
+func (m model) current() *storage.Shortcut {
+	if m.cursor >= len(m.lines) || m.lines[m.cursor].item < 0 {
+		return nil
+	}
+	return &m.shortcuts[m.lines[m.cursor].item]
+}
+
+func (m model) currentName() string {
+	if sc := m.current(); sc != nil {
+		return sc.Name
+	}
+	return ""
+}
+
+// The order line is shown once it differs from the default
+func (m model) showsOrder() bool {
+	return m.sortMode != SortByName || m.grouped
+}
+
+// Edit the shortcut under the cursor
+func (m *model) startEdit(mode editMode) {
+	if sc := m.current(); sc != nil {
+		m.edit.start(mode, []*storage.Shortcut{sc})
+	}
+}
+
+// Carry renames over to the marks and drop deleted shortcuts from the list
+func (m *model) applyEdit(result editResult) {
+	keep := m.currentName()
+	if result.renamedFrom != "" {
+		if _, ok := m.marked[result.renamedFrom]; ok {
+			delete(m.marked, result.renamedFrom)
+			m.marked[result.renamedTo] = struct{}{}
+		}
+	}
+
+	for _, name := range result.deleted {
+		for i, sc := range m.shortcuts {
+			if sc.Name == name {
+				m.shortcuts = append(m.shortcuts[:i:i], m.shortcuts[i+1:]...)
+				break
+			}
+		}
+		delete(m.marked, name)
+	}
+	m.layout(keep)
+}
+
+func (m model) toggleMark(name string) {
+	if _, ok := m.marked[name]; ok {
+		delete(m.marked, name)
+		return
+	}
+	m.marked[name] = struct{}{}
+}
+
+// Mark every listed shortcut, or clear the marks if all are already marked.
+// Shortcuts hidden by the tag filter are left alone.
+func (m model) toggleAll() {
+	names := m.listedNames()
+	all := m.countMarked(names) == len(names)
+	for _, name := range names {
+		if all {
+			delete(m.marked, name)
+		} else {
+			m.marked[name] = struct{}{}
+		}
+	}
+}
+
+// Names of the listed shortcuts, once each even when grouping repeats them
+func (m model) listedNames() []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, line := range m.lines {
+		if line.item < 0 {
+			continue
+		}
+		name := m.shortcuts[line.item].Name
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (m model) countMarked(names []string) int {
+	n := 0
+	for _, name := range names {
+		if _, ok := m.marked[name]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Marked shortcuts in list order, or the one under the cursor if none are
+func (m model) markedShortcuts() []storage.Shortcut {
+	var chosen []storage.Shortcut
+	seen := make(map[string]struct{})
+	for _, line := range m.lines {
+		if line.item < 0 {
+			continue
+		}
+		sc := m.shortcuts[line.item]
+		if _, ok := m.marked[sc.Name]; !ok {
+			continue
+		}
+		if _, ok := seen[sc.Name]; !ok {
+			seen[sc.Name] = struct{}{}
+			chosen = append(chosen, sc)
+		}
+	}
+	if len(chosen) == 0 {
+		if sc := m.current(); sc != nil {
+			chosen = append(chosen, *sc)
+		}
+	}
+	return chosen
+}
+
 func (m model) View() string {
 	if m.quitting {
 		return ""
@@ -94,22 +664,260 @@ func (m model) View() string {
 
 	var s strings.Builder
 
-	s.WriteString("Select a shortcut (up/down or j/k to move, Enter to select, q to quit):\n\n")
+	if m.showHelp {
+		s.WriteString(m.helpView())
+		return m.fit(s.String())
+	}
+	if m.browse != nil {
+		return m.browseView()
+	}
+
+	s.WriteString(m.header() + "\n")
+	if m.showsOrder() {
+		order := "Sorted by " + sortModeTitles[m.sortMode]
+		if m.grouped {
+			order += ", grouped by tag"
+		}
+		s.WriteString(m.applyStyle(order, m.styles.more) + "\n")
+	}
+	if m.showsTagBar() {
+		bar, _ := m.renderTagBar()
+		s.WriteString(bar + "\n")
+	}
+	s.WriteString("\n")
+
+	start := m.offset
+	end := min(start+m.listHeight(), len(m.lines))
+	if above := countShortcuts(m.lines[:start]); above > 0 {
+		s.WriteString(m.applyStyle(fmt.Sprintf("  ↑ %d more\n", above), m.styles.more))
+	}
+
+	for i := start; i < end; i++ {
+		line := m.lines[i]
+		if line.item < 0 {
+			s.WriteString(m.applyStyle(fmt.Sprintf("  %s (%d)", line.header, line.count), m.styles.matchedTag) + "\n")
+			continue
+		}
+
+		row, _ := m.renderRow(i)
+		s.WriteString(row + "\n")
+	}
+
+	if below := countShortcuts(m.lines[end:]); below > 0 {
+		s.WriteString(m.applyStyle(fmt.Sprintf("  ↓ %d more\n", below), m.styles.more))
+	}
+
+	switch {
+	case len(m.shortcuts) == 0:
+		s.WriteString(fmt.Sprintf("No shortcuts left (%s)\n", m.keymap.hints(string(actQuit), "to quit")))
+	case len(m.lines) == 0:
+		s.WriteString("No shortcuts have these tags\n")
+	}
+
+	if m.multi {
+		names := m.listedNames()
+		s.WriteString(fmt.Sprintf("\n%d of %d marked\n", m.countMarked(names), len(names)))
+	}
 
-	for i, shortcut := range m.shortcuts {
-		cursor := " "
-		if m.cursor == i {
-			cursor = m.applyStyle(">", m.styles.cursor)
+	if m.edit.store != nil {
+		s.WriteString("\n")
+		if m.edit.active() {
+			s.WriteString(m.edit.render())
+		} else {
+			s.WriteString(m.keymap.hints(
+				string(actRename), "rename",
+				string(actEditPath), "edit path",
+				string(actTags), "tags",
+				string(actDelete), "delete",
+			) + "\n")
+		}
+		if m.edit.status != "" {
+			s.WriteString(m.applyStyle(m.edit.status, m.styles.more) + "\n")
 		}
+	}
+
+	return m.fit(s.String())
+}
 
-		highlightedName := highlightByTokens(shortcut.Name, m.query, m.useColor, m.styles.highlight)
-		highlightedPath := highlightByTokens(shortcut.Path, m.query, m.useColor, m.styles.highlight)
-		tagStr := m.renderTags(shortcut.Tags)
+func countShortcuts(lines []listLine) int {
+	n := 0
+	for _, line := range lines {
+		if line.item >= 0 {
+			n++
+		}
+	}
+	return n
+}
 
-		s.WriteString(fmt.Sprintf("%s %d. %s -> %s%s\n", cursor, i+1, highlightedName, highlightedPath, tagStr))
+// Title line with the keys that matter most, from the active keymap
+func (m model) header() string {
+	move := m.keymap.describe(actUp, 1) + "/" + m.keymap.describe(actDown, 1)
+	if up, down := m.keymap.keys(actUp), m.keymap.keys(actDown); len(up) > 1 && len(down) > 1 {
+		move += " or " + up[1] + "/" + down[1]
 	}
 
-	return s.String()
+	var parts []string
+	parts = append(parts, move+" to move")
+	if m.multi {
+		parts = append(parts,
+			m.keymap.describe(actMark, 1)+" to mark",
+			m.keymap.describe(actMarkAll, 1)+" to mark all",
+			m.keymap.describe(actSelect, 1)+" to confirm")
+	} else {
+		parts = append(parts, m.keymap.describe(actSelect, 1)+" to select")
+	}
+	parts = append(parts,
+		m.keymap.describe(actQuit, 1)+" to quit",
+		m.keymap.describe(actHelp, 1)+" for help")
+
+	title := "Select a shortcut"
+	if m.multi {
+		title = "Select shortcuts"
+	}
+	return fmt.Sprintf("%s (%s):", title, strings.Join(parts, ", "))
+}
+
+// Help overlay for the list, or for the folders while browsing
+func (m model) helpView() string {
+	if m.browse != nil {
+		return m.keymap.helpView([]action{
+			actUp, actDown, actPageUp, actPageDown, actTop, actBottom,
+			actRight, actLeft, actFilter, actSelect, actHelp, actQuit,
+		}, map[action]string{
+			actTop:    "go to ./",
+			actBottom: "go to the last folder",
+			actRight:  "open the folder",
+			actLeft:   "go up a folder, back to the list from the shortcut",
+			actFilter: "filter the folders",
+			actSelect: "select the folder",
+		})
+	}
+	return m.keymap.helpView(m.helpActions(), map[action]string{
+		actFocus: "switch between the list and the tag bar",
+		actLeft:  "previous tag in the tag bar",
+		actRight: "browse the shortcut's folders, next tag in the tag bar",
+	})
+}
+
+// Actions listed in the list's help overlay
+func (m model) helpActions() []action {
+	actions := []action{
+		actUp, actDown, actPageUp, actPageDown, actTop, actBottom,
+		actSort, actGroup, actFocus, actLeft, actRight, actTagOp, actSelect,
+	}
+	if m.multi {
+		actions = append(actions, actMark, actMarkAll)
+	}
+	if m.edit.store != nil {
+		actions = append(actions, actRename, actEditPath, actTags, actDelete)
+	}
+	return append(actions, actHelp, actQuit)
+}
+
+// Never wrap: a wrapped line would push the cursor off screen
+func (m model) fit(view string) string {
+	if m.width <= 0 {
+		return view
+	}
+
+	lines := strings.Split(strings.TrimSuffix(view, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, m.width, "…")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Paths never shrink below this, the line is cut at the edge instead
+const minPathWidth = 12
+
+// Shorten path to width by dropping whole directories from the middle
+// (/home/…/repo), or characters when even the last element doesn't fit
+func middleTruncate(path string, width int) string {
+	if ansi.StringWidth(path) <= width {
+		return path
+	}
+	if width <= 1 {
+		return ansi.Truncate(path, width, "")
+	}
+
+	parts := strings.SplitAfter(path, "/")
+	if len(parts) > 2 && parts[0] == "/" {
+		// The root alone says nothing, keep it with the first directory
+		parts = append([]string{"/" + parts[1]}, parts[2:]...)
+	}
+	keep := func(head, tail int) string {
+		return strings.Join(parts[:head], "") + "…/" + strings.Join(parts[tail:], "")
+	}
+
+	// Grow the kept tail and head one element at a time, tail first
+	head, tail := 0, len(parts)
+	for grown := true; grown; {
+		grown = false
+		if tail-1 > head && ansi.StringWidth(keep(head, tail-1)) <= width {
+			tail--
+			grown = true
+		}
+		if head+1 < tail && ansi.StringWidth(keep(head+1, tail)) <= width {
+			head++
+			grown = true
+		}
+	}
+	if tail < len(parts) {
+		return keep(head, tail)
+	}
+
+	left := (width - 1) / 2
+	right := width - 1 - left
+	return ansi.Truncate(path, left, "") + "…" + ansi.TruncateLeft(path, ansi.StringWidth(path)-right, "")
+}
+
+// A tag as shown at the end of a row, with the columns it covers
+type tagChip struct {
+	tag        string
+	start, end int
+}
+
+// Render the shortcut on line i, and where its tags ended up so a click
+// can find them
+func (m model) renderRow(i int) (string, []tagChip) {
+	line := m.lines[i]
+	shortcut := m.shortcuts[line.item]
+
+	cursor := " "
+	if m.cursor == i {
+		cursor = m.applyStyle(">", m.styles.cursor)
+	}
+	if m.multi {
+		if _, ok := m.marked[shortcut.Name]; ok {
+			cursor += " [x]"
+		} else {
+			cursor += " [ ]"
+		}
+	}
+
+	prefix := fmt.Sprintf("%s %d. ", cursor, line.number)
+	tagStr := m.renderTags(shortcut.Tags)
+	path := shortcut.Path
+	if m.width > 0 {
+		room := m.width - ansi.StringWidth(prefix+shortcut.Name+" -> "+tagStr)
+		path = middleTruncate(path, max(room, minPathWidth))
+	}
+
+	highlightedName := highlightByTokens(shortcut.Name, m.query, m.useColor, m.styles.highlight)
+	highlightedPath := path
+	if m.useColor {
+		highlightedPath = highlightWith(path, m.query, &m.styles.path, m.styles.highlight)
+	}
+
+	var chips []tagChip
+	x := ansi.StringWidth(prefix+shortcut.Name+" -> "+path) + len(" [")
+	for _, tag := range shortcut.Tags {
+		w := ansi.StringWidth(tag)
+		chips = append(chips, tagChip{tag: tag, start: x, end: x + w})
+		x += w + len(", ")
+	}
+
+	return fmt.Sprintf("%s%s -> %s%s", prefix, highlightedName, highlightedPath, tagStr), chips
 }
 
 func (m model) renderTags(tags []string) string {
@@ -119,7 +927,7 @@ func (m model) renderTags(tags []string) string {
 
 	rendered := make([]string, len(tags))
 	for i, tag := range tags {
-		if _, ok := m.tagFilter[strings.ToLower(tag)]; ok {
+		if m.tagActive(tag) {
 			rendered[i] = m.applyStyle(tag, m.styles.matchedTag)
 			continue
 		}
@@ -137,85 +945,101 @@ func (m model) applyStyle(text string, style lipgloss.Style) string {
 }
 
 func highlightByTokens(text, query string, useColor bool, style lipgloss.Style) string {
-	if !useColor || strings.TrimSpace(query) == "" {
+	if !useColor {
 		return text
 	}
+	return highlightWith(text, query, nil, style)
+}
+
+// Highlight query tokens in text with style, and render the rest with base
+// unless it's nil
+func highlightWith(text, query string, base *lipgloss.Style, style lipgloss.Style) string {
+	plain := func(segment string) string {
+		if base == nil {
+			return segment
+		}
+		return base.Render(segment)
+	}
 
 	tokens := queryTokens(query)
 	if len(tokens) == 0 {
-		return text
+		return plain(text)
 	}
 
-	lowerText := strings.ToLower(text)
-	highlightMask := make([]bool, len(text))
+	// Match on runes folded one at a time so positions map straight back
+	// to the original text; strings.ToLower can change byte lengths
+	folded := make([]rune, 0, len(text))
+	offsets := make([]int, 0, len(text)+1)
+	for i, r := range text {
+		folded = append(folded, unicode.ToLower(r))
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(text))
 
+	highlightMask := make([]bool, len(folded))
 	for _, token := range tokens {
-		start := 0
-		for {
-			idx := strings.Index(lowerText[start:], token)
-			if idx == -1 {
-				break
+		needle := []rune(token)
+		for i := 0; i+len(needle) <= len(folded); {
+			if !runesHavePrefix(folded[i:], needle) {
+				i++
+				continue
 			}
-
-			idx += start
-			for i := idx; i < idx+len(token) && i < len(highlightMask); i++ {
-				highlightMask[i] = true
+			for j := i; j < i+len(needle); j++ {
+				highlightMask[j] = true
 			}
-			start = idx + len(token)
+			i += len(needle)
 		}
 	}
 
+	// Never split a grapheme cluster (e.g. a base letter and its accent)
+	// across styled and unstyled segments
 	var b strings.Builder
 	inHighlight := false
 	segmentStart := 0
+	runeIdx := 0
 
-	for i := 0; i < len(text); i++ {
-		if highlightMask[i] == inHighlight {
-			continue
+	flush := func(end int) {
+		if end == segmentStart {
+			return
 		}
-
-		segment := text[segmentStart:i]
+		segment := text[segmentStart:end]
 		if inHighlight {
 			b.WriteString(style.Render(segment))
 		} else {
-			b.WriteString(segment)
+			b.WriteString(plain(segment))
 		}
-
-		inHighlight = highlightMask[i]
-		segmentStart = i
+		segmentStart = end
 	}
 
-	tail := text[segmentStart:]
-	if inHighlight {
-		b.WriteString(style.Render(tail))
-	} else {
-		b.WriteString(tail)
+	graphemes := uniseg.NewGraphemes(text)
+	for graphemes.Next() {
+		start, end := graphemes.Positions()
+
+		highlighted := false
+		for ; runeIdx < len(folded) && offsets[runeIdx] < end; runeIdx++ {
+			highlighted = highlighted || highlightMask[runeIdx]
+		}
+
+		if highlighted != inHighlight {
+			flush(start)
+			inHighlight = highlighted
+		}
 	}
+	flush(len(text))
 
 	return b.String()
 }
 
-func queryTokens(query string) []string {
-	rawTokens := strings.Fields(strings.ToLower(strings.TrimSpace(query)))
-	if len(rawTokens) == 0 {
-		return nil
-	}
-
-	unique := make(map[string]struct{}, len(rawTokens))
-	for _, token := range rawTokens {
-		unique[token] = struct{}{}
+func runesHavePrefix(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
 	}
-
-	tokens := make([]string, 0, len(unique))
-	for token := range unique {
-		tokens = append(tokens, token)
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
 	}
-
-	sort.Slice(tokens, func(i, j int) bool {
-		return len(tokens[i]) > len(tokens[j])
-	})
-
-	return tokens
+	return true
 }
 
 func shouldUseColor(noColor bool) bool {
@@ -227,37 +1051,59 @@ func shouldUseColor(noColor bool) bool {
 	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
 }
 
-func newSelectorStyles(renderer *lipgloss.Renderer) selectorStyles {
+func newSelectorStyles(renderer *lipgloss.Renderer, theme config.Theme) selectorStyles {
 	return selectorStyles{
-		cursor:     renderer.NewStyle().Foreground(lipgloss.Color("212")).Bold(true),
-		highlight:  renderer.NewStyle().Foreground(lipgloss.Color("11")).Bold(true),
-		tag:        renderer.NewStyle(), // keep default terminal color for non matching tags
-		matchedTag: renderer.NewStyle().Foreground(lipgloss.Color("39")),
+		cursor:     themeStyle(renderer, theme.Cursor),
+		highlight:  themeStyle(renderer, theme.Highlight),
+		tag:        themeStyle(renderer, theme.Tag),
+		matchedTag: themeStyle(renderer, theme.MatchedTag),
+		path:       themeStyle(renderer, theme.Path),
+		more:       themeStyle(renderer, theme.Status),
 	}
 }
 
 // Run the interactive selector
-func RunSelector(shortcuts []storage.Shortcut, opts SelectorOptions) (string, error) {
+func RunSelector(shortcuts []storage.Shortcut, opts SelectorOptions) (*storage.Shortcut, error) {
+	// stderr/Error Output for bash function
+	m, err := runSelector(shortcuts, opts, os.Stdin, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	return m.selected, nil
+}
+
+// Run the selector in multi-select mode and return the marked shortcuts
+func RunMultiSelector(shortcuts []storage.Shortcut, opts SelectorOptions) ([]storage.Shortcut, error) {
+	opts.Multi = true
+	m, err := runSelector(shortcuts, opts, os.Stdin, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	return m.chosen, nil
+}
+
+func runSelector(shortcuts []storage.Shortcut, opts SelectorOptions, in io.Reader, out io.Writer) (model, error) {
 	if len(shortcuts) == 0 {
-		return "", fmt.Errorf("no shortcuts to select from")
+		return model{}, fmt.Errorf("no shortcuts to select from")
 	}
 
-	// stderr/Error Output for bash function
-	p := tea.NewProgram(
-		InitialModel(shortcuts, opts),
-		tea.WithInput(os.Stdin),
-		tea.WithOutput(os.Stderr),
-	)
+	programOpts := []tea.ProgramOption{tea.WithInput(in), tea.WithOutput(out)}
+	if opts.Mouse {
+		// Clicks report screen rows, which only match the view's rows when
+		// it starts at the top of the screen
+		programOpts = append(programOpts, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(InitialModel(shortcuts, opts), programOpts...)
 
 	finalModel, err := p.Run()
 	if err != nil {
-		return "", err
+		return model{}, err
 	}
 
 	m := finalModel.(model)
-	if m.selected != nil {
-		return m.selected.Path, nil
+	if m.selected == nil {
+		return model{}, fmt.Errorf("no selection made")
 	}
 
-	return "", fmt.Errorf("no selection made")
+	return m, nil
 }
@@ -0,0 +1,42 @@
+//go:build !notui
+
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+)
+
+func benchShortcuts(n int) []storage.Shortcut {
+	shortcuts := make([]storage.Shortcut, n)
+	for i := range shortcuts {
+		name := fmt.Sprintf("proj-%06d", i)
+		shortcuts[i] = storage.Shortcut{
+			ID:   i + 1,
+			Name: name,
+			Path: fmt.Sprintf("/home/dev/src/group-%03d/%s", i%500, name),
+			Tags: []string{"go", "work"},
+		}
+	}
+	return shortcuts
+}
+
+func BenchmarkSelectorView(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			opts := SelectorOptions{Query: "group-04 proj", FilterTags: []string{"go"}}
+			m := forceColor(InitialModel(benchShortcuts(n), opts))
+			sized, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+			m = sized.(model)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = m.View()
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+//go:build !notui
+
+package ui
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var sgrPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func FuzzHighlightByTokens(f *testing.F) {
+	for _, seed := range []struct{ text, query string }{
+		{"/home/dev/src/api", "src api"},
+		{"İstanbul", "i"},
+		{"ǅemal", "ǆ"},
+		{"Straße", "SS"},
+		{"café", "e"},
+		{"日本語のパス", "本"},
+		{"👩‍💻 work", "💻"},
+		{"\xff\xfe", "\xfe"},
+		{"ΣΊΣΥΦΟΣ", "σ"},
+	} {
+		f.Add(seed.text, seed.query)
+	}
+
+	renderer := lipgloss.NewRenderer(io.Discard)
+	renderer.SetColorProfile(termenv.ANSI256)
+	style := renderer.NewStyle().Bold(true)
+
+	f.Fuzz(func(t *testing.T, text, query string) {
+		if strings.Contains(text, "\x1b") {
+			t.Skip("escape sequences in the text are indistinguishable from styling")
+		}
+
+		got := highlightByTokens(text, query, true, style)
+
+		if stripped := sgrPattern.ReplaceAllString(got, ""); stripped != text {
+			t.Fatalf("highlighting changed the text: %q -> %q", text, stripped)
+		}
+		if utf8.ValidString(text) && !utf8.ValidString(got) {
+			t.Fatalf("highlighting split a rune: %q", got)
+		}
+	})
+}
+
+func TestHighlightByTokens_UnicodeCaseFolding(t *testing.T) {
+	renderer := lipgloss.NewRenderer(io.Discard)
+	renderer.SetColorProfile(termenv.ANSI256)
+	style := renderer.NewStyle().Bold(true)
+
+	cases := []struct {
+		text, query, want string
+	}{
+		// İ lowercases to two runes with strings.ToLower; byte masks misaligned
+		{"İstanbul/x", "x", "İstanbul/" + style.Render("x")},
+		{"ÉCOLE", "é", style.Render("É") + "COLE"},
+		// the accent is a separate rune but belongs to the highlighted e
+		{"cafés", "e", "caf" + style.Render("é") + "s"},
+	}
+
+	for _, c := range cases {
+		if got := highlightByTokens(c.text, c.query, true, style); got != c.want {
+			t.Errorf("highlightByTokens(%q, %q) = %q, want %q", c.text, c.query, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+//go:build !notui
+
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mikul1999-pixel/fs/internal/storage"
+)
+
+func goldenShortcuts() []storage.Shortcut {
+	return []storage.Shortcut{
+		{Name: "api", Path: "/home/dev/src/api", Tags: []string{"go", "proj"}},
+		{Name: "docs", Path: "/home/dev/notes/docs"},
+		{Name: "web", Path: "/home/dev/src/web", Tags: []string{"frontend", "proj"}},
+	}
+}
+
+func TestSelectorGolden_Navigation(t *testing.T) {
+	d := newDriver(t, InitialModel(goldenShortcuts(), SelectorOptions{NoColor: true}))
+
+	d.snapshot("initial").
+		press("down").snapshot("down").
+		press("j", "j").snapshot("clamped at bottom").
+		press("up", "k", "k").snapshot("clamped at top").
+		press("j", "enter")
+
+	d.assertGolden("navigation")
+
+	m := d.model.(model)
+	if !d.done {
+		t.Fatal("expected program to quit after enter")
+	}
+	if m.selected == nil || m.selected.Path != "/home/dev/notes/docs" {
+		t.Fatalf("expected docs to be selected, got %+v", m.selected)
+	}
+}
+
+func TestSelectorGolden_HighlightAndTags(t *testing.T) {
+	// Every row has a filter tag, as when the store searched by it
+	shortcuts := goldenShortcuts()
+	shortcuts[1].Tags = []string{"proj"}
+
+	opts := SelectorOptions{Query: "src api", FilterTags: []string{"PROJ"}}
+	d := newDriver(t, forceColor(InitialModel(shortcuts, opts)))
+
+	d.snapshot("stripped")
+	d.withColor().snapshot("color").press("down").snapshot("color, cursor on docs")
+
+	d.assertGolden("highlight")
+}
+
+func TestSelectorGolden_Cancel(t *testing.T) {
+	d := newDriver(t, InitialModel(goldenShortcuts(), SelectorOptions{NoColor: true}))
+
+	d.press("down").snapshot("before esc").press("esc").snapshot("after esc")
+	d.assertGolden("cancel")
+
+	m := d.model.(model)
+	if !d.done || m.selected != nil {
+		t.Fatalf("expected cancelled selection, got done=%v selected=%+v", d.done, m.selected)
+	}
+}
+
+func TestRunSelector_ProgramSelectsFromInput(t *testing.T) {
+	var out bytes.Buffer
+
+	m, err := runSelector(goldenShortcuts(), SelectorOptions{NoColor: true}, strings.NewReader("\x1b[B\x1b[B\r"), &out)
+	if err != nil {
+		t.Fatalf("selector failed: %v", err)
+	}
+	if m.selected.Path != "/home/dev/src/web" {
+		t.Fatalf("expected web path, got %q", m.selected.Path)
+	}
+}
+
+func TestRunSelector_ProgramCancelled(t *testing.T) {
+	var out bytes.Buffer
+
+	if _, err := runSelector(goldenShortcuts(), SelectorOptions{NoColor: true}, strings.NewReader("q"), &out); err == nil {
+		t.Fatal("expected error when selection is cancelled")
+	}
+}
+
+func TestSelectorGolden_MultiSelect(t *testing.T) {
+	d := newDriver(t, InitialModel(goldenShortcuts(), SelectorOptions{NoColor: true, Multi: true}))
+
+	d.snapshot("initial").
+		press("space", "down", "down", "space").snapshot("api and web marked").
+		press("a").snapshot("all marked").
+		press("a").snapshot("all cleared").
+		press("up", "space", "enter")
+
+	d.assertGolden("multi_select")
+
+	m := d.model.(model)
+	if len(m.chosen) != 1 || m.chosen[0].Name != "docs" {
+		t.Fatalf("expected only docs chosen, got %+v", m.chosen)
+	}
+}
+
+func TestModelUpdate_MultiSelectDefaultsToCursor(t *testing.T) {
+	d := newDriver(t, InitialModel(goldenShortcuts(), SelectorOptions{NoColor: true, Multi: true}))
+	d.press("down", "enter")
+
+	m := d.model.(model)
+	if len(m.chosen) != 1 || m.chosen[0].Name != "docs" {
+		t.Fatalf("expected shortcut under cursor when nothing is marked, got %+v", m.chosen)
+	}
+}
+
+func TestModelUpdate_MultiSelectKeepsListOrder(t *testing.T) {
+	d := newDriver(t, InitialModel(goldenShortcuts(), SelectorOptions{NoColor: true, Multi: true}))
+	d.press("down", "down", "space", "up", "up", "space", "enter")
+
+	m := d.model.(model)
+	if got := len(m.chosen); got != 2 || m.chosen[0].Name != "api" || m.chosen[1].Name != "web" {
+		t.Fatalf("expected api and web in list order, got %+v", m.chosen)
+	}
+}
+
+func TestSelectorGolden_Viewport(t *testing.T) {
+	d := newDriver(t, InitialModel(benchShortcuts(30), SelectorOptions{NoColor: true}))
+	d.send(tea.WindowSizeMsg{Width: 60, Height: 10})
+
+	d.snapshot("top").
+		press("pgdown").snapshot("page down").
+		press("G").snapshot("end").
+		press("up", "up", "up", "up", "up", "up", "up").snapshot("scrolled up past the window").
+		press("g").snapshot("home")
+
+	d.assertGolden("viewport")
+}
@@ -1,10 +1,14 @@
+//go:build !notui
+
 package ui
 
 import (
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/mikul1999-pixel/fs/internal/storage"
 )
 
@@ -84,3 +88,124 @@ func TestQueryTokens_DedupesAndSortsByLength(t *testing.T) {
 		t.Fatalf("expected longest token first, got %v", tokens)
 	}
 }
+
+func TestMiddleTruncate(t *testing.T) {
+	cases := []struct {
+		path  string
+		width int
+		want  string
+	}{
+		{"/home/dev/repo", 20, "/home/dev/repo"},
+		{"/home/dev/src/github.com/acme/repo", 20, "/home/…/acme/repo"},
+		{"/home/dev/src/github.com/acme/repo", 12, "/home/…/repo"},
+		{"/home/dev/src/repo", 6, "…/repo"},
+		{"/home/dev/a-very-long-repository-name", 12, "/home…y-name"},
+		{"relative/dir/with/parts", 14, "…/with/parts"},
+		{"/home/dev/日本語/リポジトリ", 14, "…/リポジトリ"},
+		{"/x", 1, "/"},
+	}
+
+	for _, c := range cases {
+		got := middleTruncate(c.path, c.width)
+		if got != c.want {
+			t.Errorf("middleTruncate(%q, %d) = %q, want %q", c.path, c.width, got, c.want)
+		}
+		if w := ansi.StringWidth(got); w > c.width {
+			t.Errorf("middleTruncate(%q, %d) is %d cells wide", c.path, c.width, w)
+		}
+	}
+}
+
+func TestModelUpdate_ViewportFollowsCursor(t *testing.T) {
+	m := InitialModel(benchShortcuts(50), SelectorOptions{NoColor: true})
+	d := newDriver(t, m)
+	d.send(tea.WindowSizeMsg{Width: 80, Height: 10})
+
+	d.press("G")
+	got := d.model.(model)
+	if got.cursor != 49 || got.offset != 45 {
+		t.Fatalf("expected cursor 49 in window starting at 45, got cursor %d offset %d", got.cursor, got.offset)
+	}
+
+	d.press("pgup")
+	got = d.model.(model)
+	if got.cursor != 44 || got.offset != 44 {
+		t.Fatalf("expected pgup to move a page and scroll, got cursor %d offset %d", got.cursor, got.offset)
+	}
+
+	d.send(tea.WindowSizeMsg{Width: 80, Height: 40})
+	got = d.model.(model)
+	if got.offset != 15 {
+		t.Fatalf("expected a taller window to pull the offset back to fill the screen, got %d", got.offset)
+	}
+	if lines := strings.Count(got.View(), "\n"); lines > 40 {
+		t.Fatalf("expected the view to fit 40 lines, got %d", lines)
+	}
+}
+
+func TestModelUpdate_Mouse(t *testing.T) {
+	clock := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	shortcuts := append(testShortcuts(), storage.Shortcut{Name: "zsh", Path: "/tmp/zsh", Tags: []string{"go"}})
+	d := newDriver(t, InitialModel(shortcuts, SelectorOptions{NoColor: true, Now: func() time.Time { return clock }}))
+	d.send(tea.WindowSizeMsg{Width: 80, Height: 20})
+
+	click := func(x, y int) {
+		d.send(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	}
+
+	d.send(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	if m := d.model.(model); m.cursor != 1 {
+		t.Fatalf("expected the wheel to move the cursor down, got %d", m.cursor)
+	}
+
+	// Rows start below the title, the tag bar and a blank line
+	click(0, 5)
+	if m := d.model.(model); m.cursor != 2 || d.done {
+		t.Fatalf("expected a click to move the cursor to zsh, got %d", m.cursor)
+	}
+
+	// "  1. api -> /tmp/api [go, proj]": go spans columns 22-23
+	click(22, 3)
+	m := d.model.(model)
+	if !m.tagActive("go") || len(m.lines) != 2 || m.currentName() != "zsh" {
+		t.Fatalf("expected clicking go to filter on it, got %d lines, cursor on %q", len(m.lines), m.currentName())
+	}
+
+	click(0, 3)
+	clock = clock.Add(time.Second)
+	click(0, 3)
+	if d.done {
+		t.Fatal("expected clicks a second apart not to select")
+	}
+	clock = clock.Add(100 * time.Millisecond)
+	click(0, 3)
+	if m := d.model.(model); !d.done || m.selected == nil || m.selected.Name != "api" {
+		t.Fatalf("expected a double click to select api, got done=%v %+v", d.done, m.selected)
+	}
+}
+
+func TestModelUpdate_NumberKeysJumpToRows(t *testing.T) {
+	d := newDriver(t, InitialModel(benchShortcuts(50), SelectorOptions{NoColor: true}))
+	d.send(tea.WindowSizeMsg{Width: 80, Height: 10})
+
+	cursor := func() int { return d.model.(model).cursor }
+	d.press("1", "2")
+	if cursor() != 11 {
+		t.Fatalf("expected 1 then 2 to go to row 12, got cursor %d", cursor())
+	}
+	if m := d.model.(model); m.offset > 11 || m.offset+m.listHeight() <= 11 {
+		t.Fatalf("expected row 12 on screen, offset %d", m.offset)
+	}
+	d.press("7")
+	if cursor() != 6 {
+		t.Fatalf("expected a 7 past the last row to start over at row 7, got cursor %d", cursor())
+	}
+	d.press("0")
+	if cursor() != 6 {
+		t.Fatalf("expected 0 to stay put, got cursor %d", cursor())
+	}
+	d.press("down", "4")
+	if cursor() != 3 {
+		t.Fatalf("expected another key to end the number, got cursor %d", cursor())
+	}
+}
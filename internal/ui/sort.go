@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+)
+
+// SortMode is the order of the selector list, cycled with s
+type SortMode int
+
+const (
+	SortByName SortMode = iota
+	SortByPath
+	SortByRecent // recently used first
+	SortByAdded  // recently added first
+	SortByScore  // best match first
+	numSortModes
+)
+
+var sortModeNames = [numSortModes]string{"name", "path", "recent", "added", "score"}
+
+var sortModeTitles = [numSortModes]string{"name", "path", "recently used", "recently added", "match score"}
+
+func (s SortMode) String() string {
+	return sortModeNames[s]
+}
+
+// Parse a sort mode as written in the config file or on the command line
+func ParseSortMode(name string) (SortMode, error) {
+	for i, n := range sortModeNames {
+		if strings.EqualFold(strings.TrimSpace(name), n) {
+			return SortMode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid sort mode '%s': expected %s", name, strings.Join(sortModeNames[:], ", "))
+}
+
+// Order shortcuts by mode, keeping equal ones in name order
+func sortShortcuts(shortcuts []storage.Shortcut, order []int, mode SortMode, query string, now time.Time) {
+	var scores map[int]float64
+	if mode == SortByScore {
+		scores = make(map[int]float64, len(order))
+		for _, i := range order {
+			scores[i] = Score(shortcuts[i], query, now)
+		}
+	}
+
+	sort.SliceStable(order, func(x, y int) bool {
+		a, b := &shortcuts[order[x]], &shortcuts[order[y]]
+		switch mode {
+		case SortByPath:
+			if a.Path != b.Path {
+				return a.Path < b.Path
+			}
+		case SortByRecent:
+			if !a.LastVisitedAt.Equal(b.LastVisitedAt) {
+				return a.LastVisitedAt.After(b.LastVisitedAt)
+			}
+		case SortByAdded:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+		case SortByScore:
+			if sa, sb := scores[order[x]], scores[order[y]]; sa != sb {
+				return sa > sb
+			}
+		}
+		return a.Name < b.Name
+	})
+}
+
+// Score how well a shortcut matches query: where each token appears in the
+// name or path, plus a bonus for frequent and recent visits. Higher is better.
+func Score(sc storage.Shortcut, query string, now time.Time) float64 {
+	name := strings.ToLower(sc.Name)
+	path := strings.ToLower(sc.Path)
+	base := strings.ToLower(filepath.Base(sc.Path))
+
+	var score float64
+	for _, token := range queryTokens(query) {
+		switch {
+		case name == token:
+			score += 100
+		case strings.HasPrefix(name, token):
+			score += 60
+		case strings.Contains(name, token):
+			score += 40
+		case strings.Contains(base, token):
+			score += 25
+		case strings.Contains(path, token):
+			score += 10
+		}
+	}
+
+	return score + 10*math.Log1p(frecency(sc, now))
+}
+
+// Visits weighted by how long ago the last one was
+func frecency(sc storage.Shortcut, now time.Time) float64 {
+	if sc.Visits == 0 || sc.LastVisitedAt.IsZero() {
+		return 0
+	}
+
+	age := now.Sub(sc.LastVisitedAt)
+	weight := 0.25
+	switch {
+	case age < time.Hour:
+		weight = 4
+	case age < 24*time.Hour:
+		weight = 2
+	case age < 7*24*time.Hour:
+		weight = 1
+	case age < 30*24*time.Hour:
+		weight = 0.5
+	}
+	return float64(sc.Visits) * weight
+}
+
+// Sorted returns the shortcuts in the order the selector lists them in mode
+func Sorted(shortcuts []storage.Shortcut, mode SortMode, query string, now time.Time) []storage.Shortcut {
+	order := make([]int, len(shortcuts))
+	for i := range order {
+		order[i] = i
+	}
+	sortShortcuts(shortcuts, order, mode, query, now)
+
+	sorted := make([]storage.Shortcut, len(order))
+	for i, j := range order {
+		sorted[i] = shortcuts[j]
+	}
+	return sorted
+}
+
+func queryTokens(query string) []string {
+	rawTokens := strings.Fields(strings.Map(unicode.ToLower, query))
+	if len(rawTokens) == 0 {
+		return nil
+	}
+
+	unique := make(map[string]struct{}, len(rawTokens))
+	for _, token := range rawTokens {
+		unique[token] = struct{}{}
+	}
+
+	tokens := make([]string, 0, len(unique))
+	for token := range unique {
+		tokens = append(tokens, token)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return len(tokens[i]) > len(tokens[j])
+	})
+
+	return tokens
+}
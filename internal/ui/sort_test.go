@@ -0,0 +1,95 @@
+//go:build !notui
+
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikul1999-pixel/fs/internal/storage"
+	"github.com/mikul1999-pixel/fs/pkg/config"
+)
+
+func TestParseSortMode_MatchesConfig(t *testing.T) {
+	if len(config.SortModes) != int(numSortModes) {
+		t.Fatalf("config lists %d sort modes, the selector has %d", len(config.SortModes), numSortModes)
+	}
+	for i, name := range config.SortModes {
+		mode, err := ParseSortMode(name)
+		if err != nil || mode != SortMode(i) {
+			t.Fatalf("ParseSortMode(%q) = %v, %v", name, mode, err)
+		}
+	}
+	if _, err := ParseSortMode("size"); err == nil {
+		t.Fatal("expected an error for an unknown sort mode")
+	}
+}
+
+func TestScore_PrefersNameMatchesThenVisits(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	shortcuts := []storage.Shortcut{
+		{Name: "backend", Path: "/src/api/backend"},
+		{Name: "api", Path: "/src/api"},
+		{Name: "apidocs", Path: "/docs"},
+		{Name: "notes", Path: "/home/notes", Visits: 50, LastVisitedAt: now.Add(-time.Minute)},
+	}
+
+	order := []int{0, 1, 2, 3}
+	sortShortcuts(shortcuts, order, SortByScore, "api", now)
+
+	var got []string
+	for _, i := range order {
+		got = append(got, shortcuts[i].Name)
+	}
+	want := []string{"api", "apidocs", "notes", "backend"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	recent := Score(storage.Shortcut{Name: "x", Visits: 3, LastVisitedAt: now.Add(-time.Hour / 2)}, "", now)
+	stale := Score(storage.Shortcut{Name: "x", Visits: 3, LastVisitedAt: now.AddDate(0, -2, 0)}, "", now)
+	if recent <= stale {
+		t.Fatalf("expected a recent visit to score higher, got %v <= %v", recent, stale)
+	}
+}
+
+func TestSelectorGolden_SortAndGroup(t *testing.T) {
+	// As found by ff -t proj; api shows up under both of its other tags
+	shortcuts := managerShortcuts()
+	shortcuts[0].Tags = []string{"go", "ops", "proj"}
+	shortcuts[1].Tags = []string{"proj"}
+	shortcuts[3].Tags = []string{"ops", "proj"}
+	opts := SelectorOptions{
+		NoColor:    true,
+		FilterTags: []string{"proj"},
+		Now:        func() time.Time { return time.Date(2026, 3, 21, 12, 0, 0, 0, time.UTC) },
+	}
+	d := newDriver(t, InitialModel(shortcuts, opts))
+
+	d.snapshot("by name").
+		press("s").snapshot("by path").
+		press("s").snapshot("recently used").
+		press("s").snapshot("recently added").
+		press("T").snapshot("grouped by tag").
+		press("down", "down", "down").snapshot("cursor skips headers").
+		press("s").snapshot("grouped by score")
+
+	d.assertGolden("sort_group")
+
+	if m := d.model.(model); m.currentName() != "docs" {
+		t.Fatalf("expected the cursor to stay on docs, got %q", m.currentName())
+	}
+}
+
+func TestModelUpdate_GroupedMultiSelectDedupes(t *testing.T) {
+	m := InitialModel(managerShortcuts(), SelectorOptions{NoColor: true, Multi: true, Group: true})
+	d := newDriver(t, m)
+	d.press("a", "enter")
+
+	chosen := d.model.(model).chosen
+	if len(chosen) != 4 {
+		t.Fatalf("expected each shortcut once, got %d: %+v", len(chosen), chosen)
+	}
+}
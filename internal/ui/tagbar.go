@@ -0,0 +1,146 @@
+//go:build !notui
+
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The tag bar sits under the selector's title and lists every tag in the
+// results. Toggling a tag re-filters the list; o switches between showing
+// shortcuts with any of the toggled tags and shortcuts with all of them.
+
+// Tags of the shortcuts being picked from, sorted
+func (m model) barTags() []string {
+	seen := make(map[string]struct{})
+	var tags []string
+	for _, sc := range m.shortcuts {
+		for _, tag := range sc.Tags {
+			key := strings.ToLower(tag)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i]) < strings.ToLower(tags[j]) })
+	return tags
+}
+
+func (m model) showsTagBar() bool {
+	return len(m.barTags()) > 0
+}
+
+// Keys while the tag bar has focus
+func (m model) updateTagBar(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tags := m.barTags()
+	switch m.keymap.lookup(msg.String()) {
+	case actQuit:
+		// esc leaves the bar, other quit keys leave the selector
+		if msg.String() == "esc" {
+			m.tagFocus = false
+			return m, nil
+		}
+		m.quitting = true
+		return m, tea.Quit
+	case actHelp:
+		m.showHelp = true
+	case actLeft, actUp:
+		m.tagCursor = max(m.tagCursor-1, 0)
+	case actRight, actDown:
+		m.tagCursor = min(m.tagCursor+1, len(tags)-1)
+	case actTop:
+		m.tagCursor = 0
+	case actBottom:
+		m.tagCursor = len(tags) - 1
+	case actMark, actSelect:
+		if m.tagCursor < len(tags) {
+			m.toggleTag(tags[m.tagCursor])
+		}
+	case actTagOp:
+		m.switchTagOp()
+	case actFocus:
+		m.tagFocus = false
+	}
+	return m, nil
+}
+
+func (m *model) switchTagOp() {
+	if m.tagOp == "and" {
+		m.tagOp = "or"
+	} else {
+		m.tagOp = "and"
+	}
+	m.layout(m.currentName())
+}
+
+// The bar and where each tag ended up, scrolled so the tag under the bar's
+// cursor is on screen
+func (m model) renderTagBar() (string, []tagChip) {
+	tags := m.barTags()
+
+	label := "Tags (any"
+	if m.tagOp == "and" {
+		label = "Tags (all"
+	}
+	label += ", " + m.keymap.describe(actTagOp, 1) + " to switch): "
+
+	// "[tag]" when toggled on, " tag " otherwise, so toggling never shifts
+	// the bar; ">" marks the bar's cursor
+	chip := func(i int) string {
+		marker := " "
+		if m.tagFocus && i == m.tagCursor {
+			marker = ">"
+		}
+		if m.tagActive(tags[i]) {
+			return marker + "[" + tags[i] + "]"
+		}
+		return marker + " " + tags[i] + " "
+	}
+
+	start := 0
+	if m.width > 0 && m.tagFocus {
+		room := m.width - ansi.StringWidth(label) - len(" …")
+		for start < m.tagCursor {
+			w := 0
+			for i := start; i <= m.tagCursor; i++ {
+				w += ansi.StringWidth(chip(i))
+			}
+			if w <= room {
+				break
+			}
+			start++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(label)
+	x := ansi.StringWidth(label)
+	if start > 0 {
+		b.WriteString(" …")
+		x += 2
+	}
+
+	var chips []tagChip
+	for i := start; i < len(tags); i++ {
+		text := chip(i)
+		w := ansi.StringWidth(text)
+		// The tag's name, past the marker and bracket
+		chips = append(chips, tagChip{tag: tags[i], start: x + 2, end: x + w - 1})
+
+		switch {
+		case m.tagFocus && i == m.tagCursor:
+			text = m.applyStyle(text, m.styles.cursor)
+		case m.tagActive(tags[i]):
+			text = m.applyStyle(text, m.styles.matchedTag)
+		}
+		b.WriteString(text)
+		x += w
+	}
+	return b.String(), chips
+}
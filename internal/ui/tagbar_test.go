@@ -0,0 +1,68 @@
+//go:build !notui
+
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSelectorGolden_TagBar(t *testing.T) {
+	// ff with no args: every tag is on offer, nothing toggled
+	d := newDriver(t, InitialModel(managerShortcuts(), SelectorOptions{NoColor: true}))
+
+	d.snapshot("initial").
+		press("tab").snapshot("tag bar focused").
+		press("right", "space").snapshot("go toggled").
+		press("right", "right", "enter").snapshot("proj toggled, any").
+		press("o").snapshot("proj toggled, all").
+		press("left", "left", "space").snapshot("go untoggled").
+		press("esc").snapshot("back to the list")
+
+	d.assertGolden("tag_bar")
+
+	m := d.model.(model)
+	if d.done || m.tagFocus {
+		t.Fatal("expected esc to leave the tag bar without quitting")
+	}
+	if len(m.lines) != 2 || m.tagOp != "and" {
+		t.Fatalf("expected proj to leave api and web, got %d lines with %q", len(m.lines), m.tagOp)
+	}
+}
+
+func TestModelUpdate_TagBarAllNarrows(t *testing.T) {
+	m := InitialModel(managerShortcuts(), SelectorOptions{NoColor: true, FilterTags: []string{"go", "proj"}})
+	if len(m.lines) != 2 {
+		t.Fatalf("expected any of go and proj to match api and web, got %d", len(m.lines))
+	}
+
+	d := newDriver(t, m)
+	d.press("o")
+	if got := d.model.(model); len(got.lines) != 1 || got.currentName() != "api" {
+		t.Fatalf("expected all of go and proj to leave api, got %d lines", len(got.lines))
+	}
+
+	// "Tags (all, o to switch):   frontend  [go] ..." puts go at 38-39
+	d.send(tea.MouseMsg{X: 38, Y: 1, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	if got := d.model.(model); got.tagActive("go") || len(got.lines) != 2 {
+		t.Fatalf("expected clicking go to untoggle it, got %d lines", len(got.lines))
+	}
+}
+
+func TestModelUpdate_MarkAllOnlyMarksVisibleRows(t *testing.T) {
+	m := InitialModel(managerShortcuts(), SelectorOptions{NoColor: true, Multi: true, FilterTags: []string{"proj"}})
+	d := newDriver(t, m)
+
+	d.press("a")
+	if view := d.model.View(); !strings.Contains(view, "2 of 2 marked") {
+		t.Fatalf("expected the count to cover the listed rows, got:\n%s", view)
+	}
+
+	d.press("enter")
+	got := d.model.(model).chosen
+	if len(got) != 2 || got[0].Name != "api" || got[1].Name != "web" {
+		t.Fatalf("expected only the tagged api and web, got %+v", got)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !notui
+
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikul1999-pixel/fs/pkg/config"
+)
+
+// Theme used when the options don't name one
+func defaultTheme() config.Theme {
+	theme, _ := config.SelectorConfig{}.ResolveTheme()
+	return theme
+}
+
+func themeOrDefault(theme *config.Theme) config.Theme {
+	if theme == nil {
+		return defaultTheme()
+	}
+	return *theme
+}
+
+// Turn a style from the theme into a lipgloss style
+func themeStyle(renderer *lipgloss.Renderer, s config.Style) lipgloss.Style {
+	style := renderer.NewStyle().
+		Bold(s.Bold).
+		Italic(s.Italic).
+		Underline(s.Underline).
+		Reverse(s.Reverse).
+		Faint(s.Faint)
+	if s.Color != "" {
+		style = style.Foreground(lipgloss.Color(s.Color))
+	}
+	return style
+}
@@ -1,11 +1,149 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
 )
 
-func GetDBPath() string {
+const (
+	// How long deleted shortcuts stay in the trash by default
+	DefaultTrashRetention = 30 * 24 * time.Hour
+
+	// Number of database backups kept by default
+	DefaultBackupKeep = 5
+
+	// How often a backup is taken automatically by default
+	DefaultBackupInterval = 24 * time.Hour
+)
+
+// Config holds every setting of fs. Values are layered as
+// defaults < config file < FS_* environment variables < command line flags.
+type Config struct {
+	DB       DBConfig       `toml:"db"`
+	Search   SearchConfig   `toml:"search"`
+	Selector SelectorConfig `toml:"selector"`
+	Output   OutputConfig   `toml:"output"`
+	Trash    TrashConfig    `toml:"trash"`
+	Backup   BackupConfig   `toml:"backup"`
+	Prompt   PromptConfig   `toml:"prompt"`
+	Hooks    HooksConfig    `toml:"hooks"`
+}
+
+type DBConfig struct {
+	Path string `toml:"path"`
+}
+
+type SearchConfig struct {
+	TagOp string   `toml:"tag_op"`
+	Roots []string `toml:"roots"`
+}
+
+type SelectorConfig struct {
+	Mouse  bool                   `toml:"mouse"`
+	Mode   string                 `toml:"mode"`
+	Sort   string                 `toml:"sort"`
+	Theme  string                 `toml:"theme"`
+	Themes map[string]ThemeConfig `toml:"themes"`
+	Keys   map[string][]string    `toml:"keys"`
+}
+
+// Selector actions that can be rebound under [selector.keys]. filter and
+// reverse only apply to 'fs ui', group and tag_op only to the picker.
+var KeyActions = []string{
+	"up", "down", "page_up", "page_down", "top", "bottom", "left", "right",
+	"select", "quit", "mark", "mark_all",
+	"rename", "edit_path", "tags", "delete",
+	"filter", "sort", "reverse", "group", "tag_op", "focus", "help",
+}
+
+// Orders the picker can list shortcuts in
+var SortModes = []string{"name", "path", "recent", "added", "score"}
+
+// How the picker asks: auto is the full-screen list unless TERM is dumb,
+// numbered a plain numbered list read from one line of input
+var SelectorModes = []string{"auto", "full", "numbered"}
+
+type OutputConfig struct {
+	Format string `toml:"format"`
+}
+
+type TrashConfig struct {
+	Retention Duration `toml:"retention"`
+}
+
+type BackupConfig struct {
+	Keep     int      `toml:"keep"`
+	Interval Duration `toml:"interval"`
+}
+
+// Shell commands for after add, remove and go. Stored for wrapper scripts to
+// read with `fs config get`; fs doesn't run them itself.
+type HooksConfig struct {
+	PostAdd    string `toml:"post_add"`
+	PostRemove string `toml:"post_remove"`
+	PostGo     string `toml:"post_go"`
+}
+
+// Duration is a time.Duration written as "90m", "12h" or "30d" in the config file
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d Duration) String() string {
+	td := time.Duration(d)
+	switch {
+	case td == 0:
+		return "0"
+	case td%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", td/(24*time.Hour))
+	case td%time.Hour == 0:
+		return fmt.Sprintf("%dh", td/time.Hour)
+	case td%time.Minute == 0:
+		return fmt.Sprintf("%dm", td/time.Minute)
+	}
+	return td.String()
+}
+
+// Default configuration
+func Default() *Config {
+	return &Config{
+		DB:       DBConfig{Path: GetDBPath()},
+		Search:   SearchConfig{TagOp: "or"},
+		Selector: SelectorConfig{Mouse: true, Mode: "auto", Sort: "name", Theme: "default"},
+		Output:   OutputConfig{Format: "text"},
+		Trash:    TrashConfig{Retention: Duration(DefaultTrashRetention)},
+		Backup: BackupConfig{
+			Keep:     DefaultBackupKeep,
+			Interval: Duration(DefaultBackupInterval),
+		},
+		Prompt: PromptConfig{
+			Format:  "[{name}{:subpath}{dirty}]",
+			Dirty:   "*",
+			Timeout: Duration(DefaultPromptTimeout),
+		},
+	}
+}
+
+func GetConfigDir() string {
 	// Use XDG_CONFIG_HOME if set, otherwise default to ~/.config
 	configDir := os.Getenv("XDG_CONFIG_HOME")
 	if configDir == "" {
@@ -13,5 +151,286 @@ func GetDBPath() string {
 		configDir = filepath.Join(home, ".config")
 	}
 
-	return filepath.Join(configDir, "fs", "shortcuts.db")
-}
\ No newline at end of file
+	return filepath.Join(configDir, "fs")
+}
+
+func GetDBPath() string {
+	return filepath.Join(GetConfigDir(), "shortcuts.db")
+}
+
+// Location of the config file, overridden by FS_CONFIG
+func GetConfigPath() string {
+	if path := os.Getenv("FS_CONFIG"); path != "" {
+		return path
+	}
+	return filepath.Join(GetConfigDir(), "config.toml")
+}
+
+// Load the effective configuration: defaults, then the config file if it
+// exists, then environment variables
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if err := LoadFile(GetConfigPath(), cfg); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+
+	// The template suggests "~/.config/fs/shortcuts.db", so ~ is home
+	cfg.DB.Path = expandHome(cfg.DB.Path)
+
+	return cfg, nil
+}
+
+// Replace a leading ~ or ~/ with the home directory. ~user is left alone.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// Decode a config file on top of cfg. Errors point at the offending line.
+func LoadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	md, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return &Error{File: path, Line: parseErr.Position.Line, Msg: parseErr.Message}
+		}
+		return &Error{File: path, Msg: strings.TrimPrefix(err.Error(), "toml: ")}
+	}
+
+	lines := keyLines(string(data))
+	var errs []error
+	for _, key := range md.Undecoded() {
+		errs = append(errs, &Error{File: path, Line: lines[key.String()], Key: key.String(), Msg: "unknown setting"})
+	}
+
+	for _, verr := range cfg.validate() {
+		verr.File = path
+		verr.Line = lines[verr.Key]
+		errs = append(errs, verr)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Override settings from FS_* environment variables
+func (c *Config) ApplyEnv() error {
+	var errs []error
+	fromEnv := make(map[string]string)
+	for _, k := range keys {
+		if k.env == "" {
+			continue
+		}
+		value := os.Getenv(k.env)
+		if value == "" {
+			continue
+		}
+		if err := k.set(c, value); err != nil {
+			errs = append(errs, &Error{Key: k.env, Msg: err.Error()})
+			continue
+		}
+		fromEnv[k.name] = k.env
+	}
+
+	for _, verr := range c.validate() {
+		if env, ok := fromEnv[verr.Key]; ok {
+			verr.Key = env
+			errs = append(errs, verr)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Check every setting, returning all problems found
+func (c *Config) Validate() error {
+	var errs []error
+	for _, err := range c.validate() {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) validate() []*Error {
+	var errs []*Error
+	invalid := func(key, format string, args ...interface{}) {
+		errs = append(errs, &Error{Key: key, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(c.DB.Path) == "" {
+		invalid("db.path", "cannot be empty")
+	}
+	if _, err := NormalizeTagOp(c.Search.TagOp); err != nil {
+		invalid("search.tag_op", "%v", err)
+	}
+	for _, root := range c.Search.Roots {
+		if strings.TrimSpace(root) == "" {
+			invalid("search.roots", "cannot contain empty paths")
+			break
+		}
+	}
+	if !contains(SelectorModes, c.Selector.Mode) {
+		invalid("selector.mode", "unknown mode %q: expected one of %s", c.Selector.Mode, strings.Join(SelectorModes, ", "))
+	}
+	if !contains(SortModes, c.Selector.Sort) {
+		invalid("selector.sort", "unknown sort mode %q: expected one of %s", c.Selector.Sort, strings.Join(SortModes, ", "))
+	}
+	if _, err := c.Selector.themeConfig(c.Selector.Theme); err != nil {
+		invalid("selector.theme", "%v", err)
+	}
+	for _, name := range sortedKeys(c.Selector.Themes) {
+		tc, err := c.Selector.themeConfig(name)
+		if err == nil {
+			_, err = tc.parse()
+		}
+		if err != nil {
+			invalid("selector.themes."+name, "%v", err)
+		}
+	}
+	for _, action := range sortedKeys(c.Selector.Keys) {
+		switch {
+		case !contains(KeyActions, action):
+			invalid("selector.keys."+action, "unknown action %q: expected one of %s", action, strings.Join(KeyActions, ", "))
+		case len(c.Selector.Keys[action]) == 0:
+			invalid("selector.keys."+action, "needs at least one key")
+		}
+	}
+	switch c.Output.Format {
+	case "text", "json":
+	default:
+		invalid("output.format", "unknown format %q: expected text or json", c.Output.Format)
+	}
+	if c.Backup.Keep < 1 {
+		invalid("backup.keep", "must be at least 1, got %d", c.Backup.Keep)
+	}
+	if _, err := ParsePromptFormat(c.Prompt.Format); err != nil {
+		invalid("prompt.format", "%v", err)
+	}
+	if c.Prompt.Timeout <= 0 {
+		invalid("prompt.timeout", "must be more than 0")
+	}
+
+	return errs
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Normalize a tag operator and its aliases to "or" or "and"
+func NormalizeTagOp(op string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(op)) {
+	case "", "or", "any":
+		return "or", nil
+	case "and", "all":
+		return "and", nil
+	default:
+		return "", fmt.Errorf("invalid tag operator '%s': expected 'or' or 'and'", op)
+	}
+}
+
+// Error is a configuration problem, with the file and line when it came from the config file
+type Error struct {
+	File string
+	Line int
+	Key  string
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File)
+		if e.Line > 0 {
+			fmt.Fprintf(&b, ":%d", e.Line)
+		}
+		b.WriteString(": ")
+	}
+	if e.Key != "" {
+		b.WriteString(e.Key)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Msg)
+	return b.String()
+}
+
+// Map dotted keys to the line they're set on
+func keyLines(data string) map[string]int {
+	lines := make(map[string]int)
+	section := ""
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section = strings.TrimSpace(strings.Trim(line, "[]"))
+			if _, ok := lines[section]; !ok {
+				lines[section] = i + 1
+			}
+			continue
+		}
+		key, _, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+		if section != "" {
+			key = section + "." + key
+		}
+		if _, ok := lines[key]; !ok {
+			lines[key] = i + 1
+		}
+	}
+	return lines
+}
+
+// Parse a duration, also accepting whole days like "30d"
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration cannot be negative: %q", value)
+	}
+	return d, nil
+}
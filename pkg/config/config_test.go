@@ -1,8 +1,12 @@
 package config
 
 import (
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetDBPath_UsesXDGConfigHome(t *testing.T) {
@@ -27,3 +31,251 @@ func TestGetDBPath_FallsBackToHomeConfig(t *testing.T) {
 		t.Fatalf("unexpected fallback db path. got=%q want=%q", got, want)
 	}
 }
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	t.Setenv("FS_CONFIG", path)
+
+	return path
+}
+
+func TestLoad_LayersDefaultsFileAndEnv(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/fs-config")
+	t.Setenv("FS_BACKUP_KEEP", "9")
+	writeConfig(t, `
+[search]
+tag_op = "and"
+roots = ["/src"]
+
+[backup]
+keep = 2
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if cfg.DB.Path != filepath.Join("/tmp/fs-config", "fs", "shortcuts.db") {
+		t.Fatalf("expected default db path, got %q", cfg.DB.Path)
+	}
+	if cfg.Search.TagOp != "and" {
+		t.Fatalf("expected tag_op from file, got %q", cfg.Search.TagOp)
+	}
+	if len(cfg.Search.Roots) != 1 || cfg.Search.Roots[0] != "/src" {
+		t.Fatalf("expected roots from file, got %v", cfg.Search.Roots)
+	}
+	if cfg.Backup.Keep != 9 {
+		t.Fatalf("expected env to override file, got keep=%d", cfg.Backup.Keep)
+	}
+	if time.Duration(cfg.Trash.Retention) != DefaultTrashRetention {
+		t.Fatalf("expected default retention, got %v", cfg.Trash.Retention)
+	}
+}
+
+func TestLoad_ExpandsTildeInDBPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("FS_DB", "")
+	writeConfig(t, "[db]\npath = \"~/.config/fs/shortcuts.db\"\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if want := filepath.Join(home, ".config", "fs", "shortcuts.db"); cfg.DB.Path != want {
+		t.Fatalf("expected ~ to be expanded. got=%q want=%q", cfg.DB.Path, want)
+	}
+}
+
+func TestLoad_MissingFileUsesDefaults(t *testing.T) {
+	t.Setenv("FS_CONFIG", filepath.Join(t.TempDir(), "missing.toml"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.Search.TagOp != "or" || cfg.Output.Format != "text" {
+		t.Fatalf("expected defaults, got %+v", cfg)
+	}
+}
+
+func TestLoad_ErrorsIncludeLineNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"invalid value", "[search]\n\ntag_op = \"xor\"\n", ":3: search.tag_op:"},
+		{"unknown key", "[output]\nformat = \"json\"\ncolour = true\n", ":3: output.colour: unknown setting"},
+		{"bad duration", "[trash]\nretention = \"soon\"\n", ":2: "},
+		{"syntax error", "[backup]\nkeep = \n", ":2: "},
+		{"unknown selector mode", "[selector]\nmode = \"tty\"\n", ":2: selector.mode: unknown mode"},
+		{"unknown theme", "[selector]\ntheme = \"solarized\"\n", ":2: selector.theme: unknown theme"},
+		{"bad theme style", "[selector.themes.mine]\ncursor = \"pink\"\n", ":1: selector.themes.mine: cursor: invalid style"},
+		{"unknown key action", "[selector.keys]\njump = [\"J\"]\n", ":2: selector.keys.jump: unknown action"},
+		{"unknown prompt field", "[prompt]\nformat = \"{branch}\"\n", ":2: prompt.format: unknown field {branch}"},
+		{"zero prompt timeout", "[prompt]\ntimeout = \"0\"\n", ":2: prompt.timeout: must be more than 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, tt.content)
+
+			_, err := Load()
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error to contain %q, got %q", tt.want, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidEnv(t *testing.T) {
+	t.Setenv("FS_CONFIG", filepath.Join(t.TempDir(), "missing.toml"))
+	t.Setenv("FS_OUTPUT", "yaml")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "FS_OUTPUT") {
+		t.Fatalf("expected error naming FS_OUTPUT, got %v", err)
+	}
+}
+
+func TestSetInFile_KeepsCommentsAndAddsSections(t *testing.T) {
+	path := writeConfig(t, "# my settings\n[search]\ntag_op = \"or\" # default\n")
+
+	if err := SetInFile(path, "search.tag_op", "and"); err != nil {
+		t.Fatalf("SetInFile returned error: %v", err)
+	}
+	if err := SetInFile(path, "backup.interval", "12h"); err != nil {
+		t.Fatalf("SetInFile returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	want := "# my settings\n[search]\ntag_op = \"and\"\n\n[backup]\ninterval = \"12h\"\n"
+	if string(data) != want {
+		t.Fatalf("unexpected config file.\ngot:\n%s\nwant:\n%s", data, want)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.Search.TagOp != "and" || time.Duration(cfg.Backup.Interval) != 12*time.Hour {
+		t.Fatalf("expected updated settings, got %+v", cfg)
+	}
+}
+
+func TestSetInFile_RejectsInvalidValue(t *testing.T) {
+	path := writeConfig(t, "")
+
+	if err := SetInFile(path, "output.format", "yaml"); err == nil {
+		t.Fatal("expected error for invalid output format")
+	}
+	if err := SetInFile(path, "nope.key", "1"); err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+}
+
+func TestParseDuration_RejectsInvalid(t *testing.T) {
+	for _, value := range []string{"soon", "-1d", "-5m", "xd"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Fatalf("expected error for %q, got nil", value)
+		}
+	}
+}
+
+func TestResolveTheme_LayersCustomOverBase(t *testing.T) {
+	writeConfig(t, `
+[selector]
+theme = "mine"
+
+[selector.themes.mine]
+base = "light"
+cursor = "#ff8800 underline"
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	theme, err := cfg.Selector.ResolveTheme()
+	if err != nil {
+		t.Fatalf("ResolveTheme returned error: %v", err)
+	}
+
+	if theme.Cursor != (Style{Color: "#ff8800", Underline: true}) {
+		t.Fatalf("expected the custom cursor, got %+v", theme.Cursor)
+	}
+	if theme.Highlight != (Style{Color: "166", Bold: true}) {
+		t.Fatalf("expected the light highlight, got %+v", theme.Highlight)
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Style
+		wantErr bool
+	}{
+		{"", Style{}, false},
+		{"212 bold", Style{Color: "212", Bold: true}, false},
+		{"#abc Reverse faint", Style{Color: "#abc", Reverse: true, Faint: true}, false},
+		{"italic", Style{Italic: true}, false},
+		{"256", Style{}, true},
+		{"1 2", Style{}, true},
+		{"#abcd", Style{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStyle(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseStyle(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Fatalf("ParseStyle(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParsePromptFormat(t *testing.T) {
+	got, err := ParsePromptFormat("[{name}{:subpath}{ #tags }]{dirty}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []PromptPart{
+		{Text: "["},
+		{Field: "name"},
+		{Field: "subpath", Before: ":"},
+		{Field: "tags", Before: " #", After: " "},
+		{Text: "]"},
+		{Field: "dirty"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParsePromptFormat = %+v, want %+v", got, want)
+	}
+
+	for _, format := range []string{"{name", "{}", "{ branch }"} {
+		if _, err := ParsePromptFormat(format); err == nil {
+			t.Errorf("ParsePromptFormat(%q) expected an error", format)
+		}
+	}
+}
+
+func TestTemplate_IsValidConfig(t *testing.T) {
+	path := writeConfig(t, Template)
+
+	if err := LoadFile(path, Default()); err != nil {
+		t.Fatalf("expected template to load cleanly, got %v", err)
+	}
+}
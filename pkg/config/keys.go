@@ -0,0 +1,354 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// A setting that can be read and written by dotted key
+type key struct {
+	name string
+	env  string
+	get  func(c *Config) interface{}
+	set  func(c *Config, value string) error
+}
+
+var keys = []key{
+	{
+		name: "db.path",
+		env:  "FS_DB",
+		get:  func(c *Config) interface{} { return c.DB.Path },
+		set:  func(c *Config, v string) error { c.DB.Path = v; return nil },
+	},
+	{
+		name: "search.tag_op",
+		env:  "FS_TAG_OP",
+		get:  func(c *Config) interface{} { return c.Search.TagOp },
+		set:  func(c *Config, v string) error { c.Search.TagOp = v; return nil },
+	},
+	{
+		name: "search.roots",
+		env:  "FS_SEARCH_ROOTS",
+		get:  func(c *Config) interface{} { return c.Search.Roots },
+		set: func(c *Config, v string) error {
+			c.Search.Roots = splitList(v)
+			return nil
+		},
+	},
+	{
+		name: "selector.mouse",
+		env:  "FS_MOUSE",
+		get:  func(c *Config) interface{} { return c.Selector.Mouse },
+		set: func(c *Config, v string) error {
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q", v)
+			}
+			c.Selector.Mouse = b
+			return nil
+		},
+	},
+	{
+		name: "selector.mode",
+		env:  "FS_SELECTOR_MODE",
+		get:  func(c *Config) interface{} { return c.Selector.Mode },
+		set:  func(c *Config, v string) error { c.Selector.Mode = strings.ToLower(strings.TrimSpace(v)); return nil },
+	},
+	{
+		name: "selector.sort",
+		env:  "FS_SORT",
+		get:  func(c *Config) interface{} { return c.Selector.Sort },
+		set:  func(c *Config, v string) error { c.Selector.Sort = strings.ToLower(strings.TrimSpace(v)); return nil },
+	},
+	{
+		name: "selector.theme",
+		env:  "FS_THEME",
+		get:  func(c *Config) interface{} { return c.Selector.Theme },
+		set:  func(c *Config, v string) error { c.Selector.Theme = v; return nil },
+	},
+	{
+		name: "output.format",
+		env:  "FS_OUTPUT",
+		get:  func(c *Config) interface{} { return c.Output.Format },
+		set:  func(c *Config, v string) error { c.Output.Format = v; return nil },
+	},
+	{
+		name: "trash.retention",
+		env:  "FS_TRASH_RETENTION",
+		get:  func(c *Config) interface{} { return c.Trash.Retention },
+		set:  func(c *Config, v string) error { return c.Trash.Retention.UnmarshalText([]byte(v)) },
+	},
+	{
+		name: "backup.keep",
+		env:  "FS_BACKUP_KEEP",
+		get:  func(c *Config) interface{} { return c.Backup.Keep },
+		set: func(c *Config, v string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return fmt.Errorf("invalid number %q", v)
+			}
+			c.Backup.Keep = n
+			return nil
+		},
+	},
+	{
+		name: "backup.interval",
+		env:  "FS_BACKUP_INTERVAL",
+		get:  func(c *Config) interface{} { return c.Backup.Interval },
+		set:  func(c *Config, v string) error { return c.Backup.Interval.UnmarshalText([]byte(v)) },
+	},
+	{
+		name: "prompt.format",
+		env:  "FS_PROMPT_FORMAT",
+		get:  func(c *Config) interface{} { return c.Prompt.Format },
+		set:  func(c *Config, v string) error { c.Prompt.Format = v; return nil },
+	},
+	{
+		name: "prompt.dirty",
+		get:  func(c *Config) interface{} { return c.Prompt.Dirty },
+		set:  func(c *Config, v string) error { c.Prompt.Dirty = v; return nil },
+	},
+	{
+		name: "prompt.timeout",
+		env:  "FS_PROMPT_TIMEOUT",
+		get:  func(c *Config) interface{} { return c.Prompt.Timeout },
+		set:  func(c *Config, v string) error { return c.Prompt.Timeout.UnmarshalText([]byte(v)) },
+	},
+	{
+		name: "hooks.post_add",
+		get:  func(c *Config) interface{} { return c.Hooks.PostAdd },
+		set:  func(c *Config, v string) error { c.Hooks.PostAdd = v; return nil },
+	},
+	{
+		name: "hooks.post_remove",
+		get:  func(c *Config) interface{} { return c.Hooks.PostRemove },
+		set:  func(c *Config, v string) error { c.Hooks.PostRemove = v; return nil },
+	},
+	{
+		name: "hooks.post_go",
+		get:  func(c *Config) interface{} { return c.Hooks.PostGo },
+		set:  func(c *Config, v string) error { c.Hooks.PostGo = v; return nil },
+	},
+}
+
+const selectorKeysPrefix = "selector.keys."
+
+func lookupKey(name string) (key, error) {
+	for _, k := range keys {
+		if k.name == name {
+			return k, nil
+		}
+	}
+
+	// Keybindings are a map, one key per action
+	if action, ok := strings.CutPrefix(name, selectorKeysPrefix); ok && action != "" {
+		return key{
+			name: name,
+			get:  func(c *Config) interface{} { return c.Selector.Keys[action] },
+			set: func(c *Config, v string) error {
+				if c.Selector.Keys == nil {
+					c.Selector.Keys = make(map[string][]string)
+				}
+				c.Selector.Keys[action] = splitList(v)
+				return nil
+			},
+		}, nil
+	}
+
+	return key{}, fmt.Errorf("unknown setting '%s'", name)
+}
+
+// Setting is one key of the effective configuration and where its value came from
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Get a setting as plain text. Lists are comma separated.
+func (c *Config) Get(name string) (string, error) {
+	k, err := lookupKey(name)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := k.get(c).(type) {
+	case []string:
+		return strings.Join(v, ","), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// Set a setting from plain text and validate the result
+func (c *Config) Set(name, value string) error {
+	k, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+
+	if err := k.set(c, value); err != nil {
+		return &Error{Key: name, Msg: err.Error()}
+	}
+
+	for _, verr := range c.validate() {
+		if verr.Key == name {
+			return verr
+		}
+	}
+	return nil
+}
+
+// Every setting with its value rendered as TOML. The source is "env" when the
+// value comes from an FS_* variable, "file" when it's set in the config file at
+// path, and "default" otherwise.
+func (c *Config) Settings(path string) []Setting {
+	fileKeys := map[string]int{}
+	if data, err := os.ReadFile(path); err == nil {
+		fileKeys = keyLines(string(data))
+	}
+
+	source := func(k key) string {
+		if k.env != "" && os.Getenv(k.env) != "" {
+			return "env " + k.env
+		}
+		if _, ok := fileKeys[k.name]; ok {
+			return "file"
+		}
+		return "default"
+	}
+
+	var settings []Setting
+	for _, k := range keys {
+		settings = append(settings, Setting{Key: k.name, Value: tomlValue(k.get(c)), Source: source(k)})
+	}
+
+	actions := make([]string, 0, len(c.Selector.Keys))
+	for action := range c.Selector.Keys {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		k, _ := lookupKey(selectorKeysPrefix + action)
+		settings = append(settings, Setting{Key: k.name, Value: tomlValue(k.get(c)), Source: source(k)})
+	}
+
+	return settings
+}
+
+// Write a single setting to the config file at path, keeping the rest of the
+// file (comments included) as is. The new value is validated first.
+func SetInFile(path, name, value string) error {
+	cfg := Default()
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if err == nil {
+		if err := LoadFile(path, cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := cfg.Set(name, value); err != nil {
+		return err
+	}
+	k, _ := lookupKey(name)
+
+	dot := strings.LastIndex(name, ".")
+	section, leaf := name[:dot], name[dot+1:]
+	updated := setLine(string(original), section, leaf, tomlValue(k.get(cfg)))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	// Never leave a file behind that fs can't read
+	if err := LoadFile(path, Default()); err != nil {
+		_ = os.WriteFile(path, original, 0644)
+		return err
+	}
+
+	return nil
+}
+
+// Replace "leaf = ..." in [section], or add it
+func setLine(data, section, leaf, value string) string {
+	assignment := fmt.Sprintf("%s = %s", leaf, value)
+	lines := strings.Split(strings.TrimRight(data, "\n"), "\n")
+	if data == "" {
+		lines = nil
+	}
+
+	current := ""
+	sectionEnd := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			current = strings.TrimSpace(strings.Trim(trimmed, "[]"))
+			continue
+		}
+		if current != section || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		sectionEnd = i
+		if k, _, ok := strings.Cut(trimmed, "="); ok && strings.Trim(strings.TrimSpace(k), `"'`) == leaf {
+			lines[i] = assignment
+			return strings.Join(lines, "\n") + "\n"
+		}
+	}
+
+	if sectionEnd == -1 {
+		for i, line := range lines {
+			if strings.TrimSpace(line) == "["+section+"]" {
+				sectionEnd = i
+			}
+		}
+	}
+
+	if sectionEnd >= 0 {
+		lines = append(lines[:sectionEnd+1], append([]string{assignment}, lines[sectionEnd+1:]...)...)
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, "["+section+"]", assignment)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Render a value as a TOML literal
+func tomlValue(v interface{}) string {
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(map[string]interface{}{"v": v}); err != nil {
+		return fmt.Sprint(v)
+	}
+	out := strings.TrimSpace(b.String())
+	return strings.TrimSpace(strings.TrimPrefix(out, "v ="))
+}
+
+func splitList(value string) []string {
+	sep := ","
+	if !strings.Contains(value, ",") && strings.Contains(value, string(os.PathListSeparator)) {
+		sep = string(os.PathListSeparator)
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// How long `fs prompt` may take by default before it gives up
+const DefaultPromptTimeout = 100 * time.Millisecond
+
+// PromptConfig shapes the segment printed by `fs prompt`. Format is text
+// with fields in braces: {name}, {subpath}, {tags} and {dirty}. Text inside
+// the braces around a field only shows when the field has a value, e.g.
+// "{:subpath}" or "{ #tags}".
+type PromptConfig struct {
+	Format  string   `toml:"format"`
+	Dirty   string   `toml:"dirty"`
+	Timeout Duration `toml:"timeout"`
+}
+
+// Fields a prompt format can show
+var PromptFields = []string{"name", "subpath", "tags", "dirty"}
+
+// PromptPart is plain text, or a field with text shown around it only when
+// the field has a value
+type PromptPart struct {
+	Text   string
+	Field  string
+	Before string
+	After  string
+}
+
+// Split a prompt format into its text and fields
+func ParsePromptFormat(format string) ([]PromptPart, error) {
+	var parts []PromptPart
+	for format != "" {
+		open := strings.IndexByte(format, '{')
+		if open == -1 {
+			parts = append(parts, PromptPart{Text: format})
+			break
+		}
+		if open > 0 {
+			parts = append(parts, PromptPart{Text: format[:open]})
+		}
+
+		end := strings.IndexByte(format[open:], '}')
+		if end == -1 {
+			return nil, fmt.Errorf("unclosed { in %q", format)
+		}
+		inner := format[open+1 : open+end]
+		format = format[open+end+1:]
+
+		part, ok := promptField(inner)
+		if !ok {
+			return nil, fmt.Errorf("unknown field {%s}: expected one of %s", inner, strings.Join(PromptFields, ", "))
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// The first field named in inner, with the text around it
+func promptField(inner string) (PromptPart, bool) {
+	best := PromptPart{}
+	at := -1
+	for _, field := range PromptFields {
+		if i := strings.Index(inner, field); i >= 0 && (at == -1 || i < at) {
+			at = i
+			best = PromptPart{Field: field, Before: inner[:i], After: inner[i+len(field):]}
+		}
+	}
+	return best, at >= 0
+}
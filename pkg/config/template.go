@@ -0,0 +1,94 @@
+package config
+
+// Template is written by `fs config edit` when there is no config file yet.
+// Every setting is commented out at its default value.
+const Template = `# fs configuration
+#
+# Settings are layered: defaults < this file < FS_* environment variables < flags.
+# Run 'fs config list' to see the effective values and where they come from.
+
+[db]
+# Database location (env: FS_DB)
+# path = "~/.config/fs/shortcuts.db"
+
+[search]
+# Default tag operator for 'fs find': or|and (env: FS_TAG_OP)
+# tag_op = "or"
+
+# Project directories, for scripts to read with 'fs config get search.roots'
+# (env: FS_SEARCH_ROOTS)
+# roots = ["~/src", "~/work"]
+
+[selector]
+# Wheel scrolling, click to move, double click to select and click a tag to
+# filter on it. The picker then takes the whole screen. (env: FS_MOUSE)
+# mouse = true
+
+# How the picker asks: full is the full-screen list, numbered prints a
+# numbered list and reads a number or the start of a name, for screen
+# readers and dumb terminals. auto is full unless TERM=dumb.
+# (env: FS_SELECTOR_MODE)
+# mode = "auto"
+
+# Order of the picker, changed with s while it's open:
+# name|path|recent|added|score (env: FS_SORT)
+# sort = "name"
+
+# Selector theme: default|dark|light|high-contrast|none, or one of your own
+# from [selector.themes.<name>] (env: FS_THEME)
+# theme = "default"
+
+# A theme of your own. Parts left out come from base. Styles are a color
+# (0-255 or #rrggbb) followed by bold, italic, underline, reverse or faint.
+# [selector.themes.mine]
+# base = "dark"
+# cursor = "212 bold"
+# highlight = "11 bold"
+# tag = ""
+# matched_tag = "39"
+# path = ""
+# status = "245"
+
+[selector.keys]
+# Keybindings by action, replacing the defaults for that action. Actions:
+# up, down, page_up, page_down, top, bottom, left, right, select, quit,
+# mark, mark_all, rename, edit_path, tags, delete, sort, focus, help, in the
+# picker group and tag_op, and in 'fs ui' filter and reverse. Press ? in the selector to see the active keys.
+# down = ["down", "j", "ctrl+n"]
+# up = ["up", "k", "ctrl+p"]
+
+[output]
+# Preferred output format for scripts: text|json (env: FS_OUTPUT)
+# format = "text"
+
+[trash]
+# How long deleted shortcuts stay in the trash, 0 keeps them (env: FS_TRASH_RETENTION)
+# retention = "30d"
+
+[backup]
+# Number of backups to keep (env: FS_BACKUP_KEEP)
+# keep = 5
+
+# Time between automatic backups, 0 disables them (env: FS_BACKUP_INTERVAL)
+# interval = "1d"
+
+[prompt]
+# Segment printed by 'fs prompt' inside a shortcut: {name}, {subpath},
+# {tags} and {dirty}. Text inside the braces only shows when the field has a
+# value, e.g. {:subpath} or { #tags}. (env: FS_PROMPT_FORMAT)
+# format = "[{name}{:subpath}{dirty}]"
+
+# Marker for uncommitted changes in the git work tree
+# dirty = "*"
+
+# Time 'fs prompt' may take. Past it the dirty check is dropped, and the
+# segment if need be. (env: FS_PROMPT_TIMEOUT)
+# timeout = "100ms"
+
+[hooks]
+# Shell commands for after add, remove and go. Stored for wrapper scripts
+# to read with 'fs config get'; fs doesn't run them itself.
+# post_add = ""
+# post_remove = ""
+# post_go = ""
+`
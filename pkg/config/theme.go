@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ThemeConfig is a theme as written in the config file. Each part is a style
+// spec: an optional color (0-255 or #rrggbb) followed by attributes, e.g.
+// "212 bold" or "#ffaf00 underline". Empty parts come from the base theme.
+type ThemeConfig struct {
+	Base       string `toml:"base"`
+	Cursor     string `toml:"cursor"`
+	Highlight  string `toml:"highlight"`
+	Tag        string `toml:"tag"`
+	MatchedTag string `toml:"matched_tag"`
+	Path       string `toml:"path"`
+	Status     string `toml:"status"`
+}
+
+// Style is a parsed style spec
+type Style struct {
+	Color     string
+	Bold      bool
+	Italic    bool
+	Underline bool
+	Reverse   bool
+	Faint     bool
+}
+
+// Theme is a resolved theme, ready for the selector
+type Theme struct {
+	Cursor     Style
+	Highlight  Style
+	Tag        Style
+	MatchedTag Style
+	Path       Style
+	Status     Style
+}
+
+// Built-in themes. "default" is dark, "none" disables colors.
+var builtinThemes = map[string]ThemeConfig{
+	"dark": {
+		Cursor:     "212 bold",
+		Highlight:  "11 bold",
+		MatchedTag: "39",
+		Status:     "245",
+	},
+	"light": {
+		Cursor:     "125 bold",
+		Highlight:  "166 bold",
+		MatchedTag: "25",
+		Path:       "238",
+		Status:     "242",
+	},
+	"high-contrast": {
+		Cursor:     "15 bold reverse",
+		Highlight:  "11 bold underline",
+		Tag:        "15",
+		MatchedTag: "14 bold",
+		Path:       "15",
+		Status:     "15",
+	},
+}
+
+// Names of the built-in themes, for help and error messages
+func BuiltinThemes() []string {
+	names := []string{"default", "none"}
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names[2:])
+	return names
+}
+
+// Resolve the configured theme, layering a user theme over its base
+func (c SelectorConfig) ResolveTheme() (Theme, error) {
+	tc, err := c.themeConfig(c.Theme)
+	if err != nil {
+		return Theme{}, err
+	}
+	return tc.parse()
+}
+
+func (c SelectorConfig) themeConfig(name string) (ThemeConfig, error) {
+	switch name {
+	case "", "default":
+		return builtinThemes["dark"], nil
+	case "none":
+		return ThemeConfig{}, nil
+	}
+	if tc, ok := builtinThemes[name]; ok {
+		return tc, nil
+	}
+
+	custom, ok := c.Themes[name]
+	if !ok {
+		return ThemeConfig{}, fmt.Errorf("unknown theme %q: expected one of %s or a [selector.themes.%s] table",
+			name, strings.Join(BuiltinThemes(), ", "), name)
+	}
+
+	base := custom.Base
+	if base == "" {
+		base = "dark"
+	}
+	if _, ok := c.Themes[base]; ok {
+		if _, builtin := builtinThemes[base]; !builtin {
+			return ThemeConfig{}, fmt.Errorf("theme %q: base must be a built-in theme, got %q", name, base)
+		}
+	}
+	merged, err := c.themeConfig(base)
+	if err != nil {
+		return ThemeConfig{}, fmt.Errorf("theme %q: %w", name, err)
+	}
+
+	override := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	override(&merged.Cursor, custom.Cursor)
+	override(&merged.Highlight, custom.Highlight)
+	override(&merged.Tag, custom.Tag)
+	override(&merged.MatchedTag, custom.MatchedTag)
+	override(&merged.Path, custom.Path)
+	override(&merged.Status, custom.Status)
+	return merged, nil
+}
+
+func (tc ThemeConfig) parse() (Theme, error) {
+	var theme Theme
+	for _, part := range []struct {
+		name string
+		spec string
+		dst  *Style
+	}{
+		{"cursor", tc.Cursor, &theme.Cursor},
+		{"highlight", tc.Highlight, &theme.Highlight},
+		{"tag", tc.Tag, &theme.Tag},
+		{"matched_tag", tc.MatchedTag, &theme.MatchedTag},
+		{"path", tc.Path, &theme.Path},
+		{"status", tc.Status, &theme.Status},
+	} {
+		style, err := ParseStyle(part.spec)
+		if err != nil {
+			return Theme{}, fmt.Errorf("%s: %w", part.name, err)
+		}
+		*part.dst = style
+	}
+	return theme, nil
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Parse a style spec like "212 bold" or "#ffaf00 underline"
+func ParseStyle(spec string) (Style, error) {
+	var style Style
+	for _, word := range strings.Fields(spec) {
+		switch strings.ToLower(word) {
+		case "bold":
+			style.Bold = true
+		case "italic":
+			style.Italic = true
+		case "underline":
+			style.Underline = true
+		case "reverse":
+			style.Reverse = true
+		case "faint":
+			style.Faint = true
+		default:
+			if style.Color != "" {
+				return Style{}, fmt.Errorf("invalid style %q: more than one color", spec)
+			}
+			if n, err := strconv.Atoi(word); err == nil && n >= 0 && n <= 255 {
+				style.Color = word
+				continue
+			}
+			if hexColor.MatchString(word) {
+				style.Color = word
+				continue
+			}
+			return Style{}, fmt.Errorf("invalid style %q: %q is not a color (0-255 or #rrggbb) or bold, italic, underline, reverse, faint", spec, word)
+		}
+	}
+	return style, nil
+}
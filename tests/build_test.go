@@ -0,0 +1,23 @@
+package tests
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// The light fs must not link the TUI, or `fs go` pays for its package init
+func TestLightBuild_LeavesOutTheTUI(t *testing.T) {
+	out, err := exec.Command("go", "list", "-deps", "-tags", "notui", "../cmd/fs").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go list failed: %v\n%s", err, out)
+	}
+
+	for _, pkg := range strings.Fields(string(out)) {
+		for _, tui := range []string{"bubbletea", "lipgloss", "termenv"} {
+			if strings.Contains(pkg, tui) {
+				t.Errorf("fs built with -tags notui depends on %s", pkg)
+			}
+		}
+	}
+}
@@ -0,0 +1,465 @@
+//go:build !windows
+
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+const shellTimeout = 10 * time.Second
+
+var (
+	buildOnce sync.Once
+	binDir    string
+	buildErr  error
+
+	donePattern = regexp.MustCompile(`__DONE__ (\d+) ([^\r\n]*)\r?\n`)
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07]*\x07|\x1b[=>]`)
+)
+
+// Build fs once per test run the way make install does: the light fs, which
+// hands the selector over to fs-tui next to it
+func buildFS(t *testing.T) string {
+	t.Helper()
+
+	buildOnce.Do(func() {
+		binDir, buildErr = os.MkdirTemp("", "fs-e2e-")
+		if buildErr != nil {
+			return
+		}
+		builds := [][]string{
+			{"build", "-tags", "notui", "-o", filepath.Join(binDir, "fs"), "../cmd/fs"},
+			{"build", "-o", filepath.Join(binDir, "fs-tui"), "../cmd/fs"},
+		}
+		for _, args := range builds {
+			out, err := exec.Command("go", args...).CombinedOutput()
+			if err != nil {
+				buildErr = fmt.Errorf("go build failed: %v\n%s", err, out)
+				return
+			}
+		}
+	})
+
+	if buildErr != nil {
+		t.Fatal(buildErr)
+	}
+	return binDir
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if binDir != "" {
+		_ = os.RemoveAll(binDir)
+	}
+	os.Exit(code)
+}
+
+// Isolated environment for the binary and the shells it runs in
+type shellEnv struct {
+	home string
+	env  []string
+}
+
+func newShellEnv(t *testing.T) *shellEnv {
+	t.Helper()
+
+	bin := buildFS(t)
+	home := t.TempDir()
+
+	env := []string{
+		"PATH=" + bin + string(os.PathListSeparator) + os.Getenv("PATH"),
+		"HOME=" + home,
+		"XDG_CONFIG_HOME=" + filepath.Join(home, ".config"),
+		"FS_DB=" + filepath.Join(home, "shortcuts.db"),
+		"FS_BACKUP_INTERVAL=0",
+		"TERM=xterm-256color",
+		"PS1=$ ",
+		"LANG=C.UTF-8",
+	}
+
+	return &shellEnv{home: home, env: env}
+}
+
+// Run fs outside the shell to prepare fixtures
+func (e *shellEnv) fs(t *testing.T, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command(filepath.Join(binDir, "fs"), args...)
+	cmd.Env = e.env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("fs %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func (e *shellEnv) mkdir(t *testing.T, name string) string {
+	t.Helper()
+
+	dir := filepath.Join(e.home, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed to create %s: %v", dir, err)
+	}
+	return dir
+}
+
+// An interactive shell attached to a pseudo-terminal
+type shellSession struct {
+	t    *testing.T
+	pty  *os.File
+	cmd  *exec.Cmd
+	fish bool
+	mu   sync.Mutex
+	out  bytes.Buffer
+	seen int
+}
+
+func startShell(t *testing.T, e *shellEnv, shell string, args ...string) *shellSession {
+	t.Helper()
+
+	cmd := exec.Command(shell, args...)
+	cmd.Env = e.env
+	cmd.Dir = e.home
+
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: 24, Cols: 120})
+	if err != nil {
+		t.Fatalf("failed to start %s in a pty: %v", shell, err)
+	}
+
+	s := &shellSession{t: t, pty: f, cmd: cmd, fish: filepath.Base(shell) == "fish"}
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := f.Read(buf)
+			if n > 0 {
+				s.mu.Lock()
+				s.out.Write(buf[:n])
+				s.mu.Unlock()
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	t.Cleanup(s.close)
+
+	if s.fish {
+		s.exec("fs init --shell fish | source")
+	} else {
+		s.exec(`eval "$(fs init)"`)
+	}
+	return s
+}
+
+// Load the output of an fs init command into the shell
+func (s *shellSession) source(initCmd string) {
+	s.t.Helper()
+	if s.fish {
+		s.exec(initCmd + " | source")
+		return
+	}
+	s.exec(`eval "$(` + initCmd + `)"`)
+}
+
+func (s *shellSession) close() {
+	_, _ = io.WriteString(s.pty, "exit\r")
+
+	done := make(chan struct{})
+	go func() {
+		_ = s.cmd.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		_ = s.cmd.Process.Kill()
+		<-done
+	}
+	_ = s.pty.Close()
+}
+
+func (s *shellSession) send(keys string) {
+	s.t.Helper()
+
+	if _, err := io.WriteString(s.pty, keys); err != nil {
+		s.t.Fatalf("failed to write to pty: %v", err)
+	}
+}
+
+// Wait until output not yet consumed matches re
+func (s *shellSession) expect(re *regexp.Regexp) []string {
+	s.t.Helper()
+
+	deadline := time.Now().Add(shellTimeout)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		pending := s.out.Bytes()[s.seen:]
+		loc := re.FindSubmatchIndex(pending)
+		if loc != nil {
+			match := make([]string, len(loc)/2)
+			for i := range match {
+				if loc[2*i] >= 0 {
+					match[i] = string(pending[loc[2*i]:loc[2*i+1]])
+				}
+			}
+			s.seen += loc[1]
+			s.mu.Unlock()
+			return match
+		}
+		s.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	s.t.Fatalf("timed out waiting for %q; output so far:\n%s", re, s.output())
+	return nil
+}
+
+func (s *shellSession) expectText(text string) {
+	s.t.Helper()
+	s.expect(regexp.MustCompile(regexp.QuoteMeta(text)))
+}
+
+// Submit a command line without waiting for it to finish
+func (s *shellSession) start(line string) {
+	s.t.Helper()
+	status := `"$?"`
+	if s.fish {
+		status = "$status"
+	}
+	s.send(line + `; printf '__%s__ %s %s\n' DONE ` + status + ` "$PWD"` + "\r")
+}
+
+// Wait for the command started last and report its exit code and the shell's cwd
+func (s *shellSession) wait() (int, string) {
+	s.t.Helper()
+
+	match := s.expect(donePattern)
+	code, err := strconv.Atoi(match[1])
+	if err != nil {
+		s.t.Fatalf("bad exit code %q", match[1])
+	}
+	return code, match[2]
+}
+
+func (s *shellSession) exec(line string) (int, string) {
+	s.t.Helper()
+	s.start(line)
+	return s.wait()
+}
+
+func (s *shellSession) output() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ansiPattern.ReplaceAllString(s.out.String(), "")
+}
+
+func samePath(t *testing.T, got, want string) bool {
+	t.Helper()
+
+	resolvedGot, err := filepath.EvalSymlinks(got)
+	if err != nil {
+		return false
+	}
+	resolvedWant, err := filepath.EvalSymlinks(want)
+	if err != nil {
+		t.Fatalf("failed to resolve %s: %v", want, err)
+	}
+	return resolvedGot == resolvedWant
+}
+
+var shells = []struct {
+	name string
+	args []string
+}{
+	{name: "bash", args: []string{"--norc", "--noprofile", "-i"}},
+	{name: "zsh", args: []string{"-f", "-i"}},
+	{name: "fish", args: []string{"--no-config", "-i"}},
+}
+
+func TestShellIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping pty shell tests in short mode")
+	}
+
+	for _, sh := range shells {
+		t.Run(sh.name, func(t *testing.T) {
+			shell, err := exec.LookPath(sh.name)
+			if err != nil {
+				t.Skipf("%s not installed", sh.name)
+			}
+
+			e := newShellEnv(t)
+			alpha := e.mkdir(t, "proj-alpha")
+			beta := e.mkdir(t, "proj-beta")
+			gone := e.mkdir(t, "gone")
+			start := e.mkdir(t, "start")
+
+			e.fs(t, "add", "alpha", alpha)
+			e.fs(t, "add", "beta", beta)
+			e.fs(t, "add", "gone", gone)
+			e.fs(t, "tag", "alpha", "proj")
+			e.fs(t, "tag", "beta", "proj")
+			if err := os.Remove(gone); err != nil {
+				t.Fatal(err)
+			}
+
+			s := startShell(t, e, shell, sh.args...)
+
+			t.Run("jump", func(t *testing.T) {
+				s.t = t
+				s.exec("cd " + start)
+
+				code, pwd := s.exec("f alpha")
+				if code != 0 || !samePath(t, pwd, alpha) {
+					t.Fatalf("f alpha: got code %d pwd %s, want 0 %s", code, pwd, alpha)
+				}
+			})
+
+			t.Run("unknown shortcut", func(t *testing.T) {
+				s.t = t
+				s.exec("cd " + start)
+
+				code, pwd := s.exec("f nope")
+				if code != 1 || !samePath(t, pwd, start) {
+					t.Fatalf("f nope: got code %d pwd %s, want 1 %s", code, pwd, start)
+				}
+			})
+
+			t.Run("usage", func(t *testing.T) {
+				s.t = t
+
+				if code, _ := s.exec("f"); code != 2 {
+					t.Fatalf("f without args: got code %d, want 2", code)
+				}
+			})
+
+			t.Run("missing directory", func(t *testing.T) {
+				s.t = t
+				s.exec("cd " + start)
+
+				code, pwd := s.exec("f gone")
+				if code != 1 || !samePath(t, pwd, start) {
+					t.Fatalf("f gone: got code %d pwd %s, want 1 %s", code, pwd, start)
+				}
+				if !strings.Contains(s.output(), "points to missing directory") {
+					t.Fatalf("expected missing directory message, got:\n%s", s.output())
+				}
+			})
+
+			t.Run("find single match", func(t *testing.T) {
+				s.t = t
+				s.exec("cd " + start)
+
+				code, pwd := s.exec("ff beta")
+				if code != 0 || !samePath(t, pwd, beta) {
+					t.Fatalf("ff beta: got code %d pwd %s, want 0 %s", code, pwd, beta)
+				}
+			})
+
+			t.Run("find selects with keys", func(t *testing.T) {
+				s.t = t
+				s.exec("cd " + start)
+
+				s.start("ff -t proj")
+				s.expectText("Select a shortcut")
+				s.send("j")
+				s.send("\r")
+
+				code, pwd := s.wait()
+				if code != 0 || !samePath(t, pwd, beta) {
+					t.Fatalf("ff -t proj: got code %d pwd %s, want 0 %s", code, pwd, beta)
+				}
+			})
+
+			t.Run("find cancelled", func(t *testing.T) {
+				s.t = t
+				s.exec("cd " + start)
+
+				s.start("ff -t proj")
+				s.expectText("Select a shortcut")
+				s.send("q")
+
+				code, pwd := s.wait()
+				if code != 1 || !samePath(t, pwd, start) {
+					t.Fatalf("cancelled ff: got code %d pwd %s, want 1 %s", code, pwd, start)
+				}
+			})
+
+			t.Run("back and forward", func(t *testing.T) {
+				s.t = t
+				s.exec("cd " + start)
+				s.exec("f alpha")
+				s.exec("f beta")
+
+				steps := []struct {
+					line string
+					want string
+				}{
+					{"fback", alpha},
+					{"fback", start},
+					{"ffwd", alpha},
+					{"ffwd", beta},
+				}
+				for _, step := range steps {
+					code, pwd := s.exec(step.line)
+					if code != 0 || !samePath(t, pwd, step.want) {
+						t.Fatalf("%s: got code %d pwd %s, want 0 %s", step.line, code, pwd, step.want)
+					}
+				}
+
+				if code, pwd := s.exec("ffwd"); code != 1 || !samePath(t, pwd, beta) {
+					t.Fatalf("ffwd past the last jump: got code %d pwd %s, want 1 %s", code, pwd, beta)
+				}
+			})
+
+			t.Run("prompt segment", func(t *testing.T) {
+				s.t = t
+				src := e.mkdir(t, "proj-alpha/src")
+				s.source("fs init --prompt --shell " + sh.name)
+
+				// fish shows it as the right prompt, the others in front of PS1
+				s.exec("cd " + src)
+				if !s.fish {
+					s.expectText("[alpha:src] $ ")
+				}
+				s.start(`printf '<%s>\n' "$FS_PROMPT"`)
+				s.expectText("<[alpha:src]>")
+				s.wait()
+
+				code, pwd := s.exec("f ..")
+				if code != 0 || !samePath(t, pwd, alpha) {
+					t.Fatalf("f ..: got code %d pwd %s, want 0 %s", code, pwd, alpha)
+				}
+				s.start(`printf '<%s>\n' "$FS_PROMPT"`)
+				s.expectText("<[alpha]>")
+				s.wait()
+			})
+
+			t.Run("find missing directory", func(t *testing.T) {
+				s.t = t
+				s.exec("cd " + start)
+
+				code, pwd := s.exec("ff gone")
+				if code != 1 || !samePath(t, pwd, start) {
+					t.Fatalf("ff gone: got code %d pwd %s, want 1 %s", code, pwd, start)
+				}
+				if !strings.Contains(s.output(), "selected path is not a directory") {
+					t.Fatalf("expected not a directory message, got:\n%s", s.output())
+				}
+			})
+		})
+	}
+}